@@ -0,0 +1,183 @@
+// Command gotoken decodes a JWT and prints its header and claims, optionally
+// verifying the signature with an HMAC secret.
+//
+// Usage:
+//
+//	gotoken [--json] [--verify --secret <secret>] <token>
+//
+// Exit codes distinguish why a token was rejected:
+//
+//	0  token decoded (and verified, if requested)
+//	1  invalid token structure
+//	2  signature verification failed
+//	3  token is expired
+//	4  other claim validation failure
+//	5  usage error
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/othonhugo/gotoken/pkg/jwt"
+)
+
+const (
+	exitOK = iota
+	exitInvalidStructure
+	exitBadSignature
+	exitExpired
+	exitInvalidClaims
+	exitUsage
+)
+
+type report struct {
+	Header         jwt.Header     `json:"header"`
+	Claims         map[string]any `json:"claims"`
+	Verified       bool           `json:"verified"`
+	SignatureValid bool           `json:"signature_valid"`
+	Error          string         `json:"error,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gotoken", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	asJSON := flags.Bool("json", false, "print the decoded token as JSON")
+	verify := flags.Bool("verify", false, "verify the signature and claims")
+	secret := flags.String("secret", "", "HMAC secret used with --verify")
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gotoken [--json] [--verify --secret <secret>] <token>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 || (*verify && *secret == "") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	token := flags.Arg(0)
+	r := report{}
+
+	header, err := jwt.ParseUnverified(token, &r.Claims)
+	if err != nil {
+		fmt.Fprintln(stderr, "gotoken:", err)
+		return exitInvalidStructure
+	}
+
+	r.Header = header
+	code := exitOK
+
+	if *verify {
+		var claims jwt.Claims
+
+		err := jwt.Unmarshal(token, &claims, []byte(*secret))
+		code = exitCode(err)
+		r.Verified = err == nil
+
+		// Claims are only validated once the signature has verified
+		r.SignatureValid = code == exitOK || code == exitExpired || code == exitInvalidClaims
+
+		if err != nil {
+			r.Error = err.Error()
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintln(stderr, "gotoken:", err)
+			return exitUsage
+		}
+
+		return code
+	}
+
+	printReport(stdout, &r, *verify)
+
+	return code
+}
+
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, jwt.ErrSignatureMismatch), errors.Is(err, jwt.ErrInvalidKeyType):
+		return exitBadSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return exitExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return exitInvalidClaims
+	}
+
+	return exitInvalidStructure
+}
+
+func printReport(w io.Writer, r *report, verify bool) {
+	fmt.Fprintln(w, "Header:")
+	fmt.Fprintf(w, "  alg: %s\n", r.Header.Alg)
+	fmt.Fprintf(w, "  typ: %s\n", r.Header.Typ)
+
+	fmt.Fprintln(w, "Claims:")
+
+	keys := make([]string, 0, len(r.Claims))
+
+	for k := range r.Claims {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", k, formatClaim(k, r.Claims[k]))
+	}
+
+	if !verify {
+		fmt.Fprintln(w, "Signature: not verified")
+		return
+	}
+
+	if !r.SignatureValid {
+		fmt.Fprintf(w, "Signature: rejected (%s)\n", r.Error)
+		return
+	}
+
+	fmt.Fprintln(w, "Signature: valid")
+
+	if r.Verified {
+		fmt.Fprintln(w, "Validation: passed")
+		return
+	}
+
+	fmt.Fprintf(w, "Validation: rejected (%s)\n", r.Error)
+}
+
+func formatClaim(name string, value any) string {
+	if n, ok := value.(float64); ok && (name == "exp" || name == "nbf" || name == "iat") {
+		return fmt.Sprintf("%.0f (%s)", n, time.Unix(int64(n), 0).UTC().Format(time.RFC3339))
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/othonhugo/gotoken/pkg/jwt"
+)
+
+// TestRun verifies output and exit codes of the command
+func TestRun(t *testing.T) {
+	secret := []byte("test-secret")
+	header := jwt.Header{Alg: jwt.HS256}
+
+	valid, _ := jwt.Marshal(header, jwt.Claims{Subject: "user123", ExpiresAt: time.Now().Add(time.Hour).Unix()}, secret)
+	expired, _ := jwt.Marshal(header, jwt.Claims{Subject: "user123", ExpiresAt: time.Now().Add(-time.Hour).Unix()}, secret)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name:     "decode without verification",
+			args:     []string{valid},
+			wantCode: exitOK,
+			wantOut:  "Signature: not verified",
+		},
+		{
+			name:     "verify valid token",
+			args:     []string{"--verify", "--secret", "test-secret", valid},
+			wantCode: exitOK,
+			wantOut:  "Signature: valid\nValidation: passed",
+		},
+		{
+			name:     "bad signature",
+			args:     []string{"--verify", "--secret", "wrong-secret", valid},
+			wantCode: exitBadSignature,
+			wantOut:  "Signature: rejected",
+		},
+		{
+			name:     "expired token",
+			args:     []string{"--verify", "--secret", "test-secret", expired},
+			wantCode: exitExpired,
+			wantOut:  `sub: "user123"`,
+		},
+		{
+			name:     "expired token with a valid signature",
+			args:     []string{"--verify", "--secret", "test-secret", expired},
+			wantCode: exitExpired,
+			wantOut:  "Signature: valid\nValidation: rejected (" + jwt.ErrTokenExpired.Error() + ")",
+		},
+		{
+			name:     "invalid structure",
+			args:     []string{"not-a-token"},
+			wantCode: exitInvalidStructure,
+		},
+		{
+			name:     "missing token",
+			args:     []string{},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "verify without secret",
+			args:     []string{"--verify", valid},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(tt.args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("output %q does not contain %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+
+	t.Run("json output", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		if code := run([]string{"--json", "--verify", "--secret", "test-secret", valid}, &stdout, &stderr); code != exitOK {
+			t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+		}
+
+		var r report
+
+		if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+			t.Fatalf("invalid JSON output: %v", err)
+		}
+
+		if !r.Verified || !r.SignatureValid || r.Header.Alg != jwt.HS256 || r.Claims["sub"] != "user123" {
+			t.Errorf("report = %+v", r)
+		}
+	})
+}
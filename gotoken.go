@@ -13,6 +13,30 @@ const (
 	// HS512 represents the HMAC-SHA512 signing algorithm.
 	HS512 = jwt.HS512
 
+	// RS256 represents the RSASSA-PKCS1-v1_5 using SHA-256 signing algorithm.
+	RS256 = jwt.RS256
+
+	// RS384 represents the RSASSA-PKCS1-v1_5 using SHA-384 signing algorithm.
+	RS384 = jwt.RS384
+
+	// RS512 represents the RSASSA-PKCS1-v1_5 using SHA-512 signing algorithm.
+	RS512 = jwt.RS512
+
+	// ES256 represents the ECDSA using P-256 and SHA-256 signing algorithm.
+	ES256 = jwt.ES256
+
+	// ES384 represents the ECDSA using P-384 and SHA-384 signing algorithm.
+	ES384 = jwt.ES384
+
+	// ES512 represents the ECDSA using P-521 and SHA-512 signing algorithm.
+	ES512 = jwt.ES512
+
+	// EdDSA represents the EdDSA signing algorithm using Ed25519 keys.
+	EdDSA = jwt.EdDSA
+
+	// None represents unsigned tokens, accepted only with jwt.UnsafeAllowNoneSignature.
+	None = jwt.None
+
 	// JWT is the type representing a JSON Web Token.
 	JWT = jwt.JWT
 )
@@ -23,12 +47,18 @@ type Header = jwt.Header
 // Claims represents the claims of a JWT.
 type Claims = jwt.Claims
 
+// Audience represents the audience claim of a JWT.
+type Audience = jwt.Audience
+
+// Option configures the behavior of Marshal and Unmarshal.
+type Option = jwt.Option
+
 // Marshal encodes the JWT header and claims into a JWS.
-func Marshal(header Header, claims any, secret []byte) (string, error) {
-	return jwt.Marshal(header, claims, secret)
+func Marshal(header Header, claims any, key any, opts ...Option) (string, error) {
+	return jwt.Marshal(header, claims, key, opts...)
 }
 
 // Unmarshal decodes the JWS into a JWT header and claims.
-func Unmarshal(jws string, claims any, secret []byte) error {
-	return jwt.Unmarshal(jws, claims, secret)
+func Unmarshal(jws string, claims any, key any, opts ...Option) error {
+	return jwt.Unmarshal(jws, claims, key, opts...)
 }
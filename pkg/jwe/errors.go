@@ -0,0 +1,41 @@
+package jwe
+
+import "errors"
+
+var (
+	// ErrInvalidToken is returned when the token is not a well-formed compact JWE
+	ErrInvalidToken = errors.New("jwe: invalid token")
+
+	// ErrInvalidKey is returned when no usable private key is provided
+	ErrInvalidKey = errors.New("jwe: invalid private key")
+
+	// ErrDecryption is returned when the content encryption key or the content cannot be decrypted
+	ErrDecryption = errors.New("jwe: decryption failed")
+)
+
+// unsupportedAlgorithmError indicates the key management algorithm is not supported
+type unsupportedAlgorithmError struct {
+	alg string
+}
+
+func (e unsupportedAlgorithmError) Error() string {
+	return "jwe: unsupported algorithm: " + e.alg
+}
+
+// unsupportedEncryptionError indicates the content encryption algorithm is not supported
+type unsupportedEncryptionError struct {
+	enc string
+}
+
+func (e unsupportedEncryptionError) Error() string {
+	return "jwe: unsupported encryption: " + e.enc
+}
+
+// unsupportedCompressionError indicates the compression algorithm is not supported
+type unsupportedCompressionError struct {
+	zip string
+}
+
+func (e unsupportedCompressionError) Error() string {
+	return "jwe: unsupported compression: " + e.zip
+}
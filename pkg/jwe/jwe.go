@@ -0,0 +1,146 @@
+// Package jwe implements decryption of compact JWE (JSON Web Encryption)
+// tokens, as used to wrap a signed JWT in a nested token.
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha1" // #nosec G505 -- RSA-OAEP is defined over SHA-1 by RFC 7518
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Constants for the supported key management and content encryption algorithms
+const (
+	RSAOAEP = "RSA-OAEP"
+	A256GCM = "A256GCM"
+)
+
+const (
+	cekSize = 32
+	ivSize  = 12
+	tagSize = 16
+)
+
+// Header represents the protected JWE header
+type Header struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Cty string `json:"cty,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	Zip string `json:"zip,omitempty"`
+}
+
+type segments struct {
+	header, encryptedKey, iv, ciphertext, tag string
+}
+
+func (s *segments) unmarshal(jwe string) error {
+	fields := strings.Split(jwe, ".")
+	if len(fields) != 5 {
+		return ErrInvalidToken
+	}
+
+	*s = segments{
+		header:       fields[0],
+		encryptedKey: fields[1],
+		iv:           fields[2],
+		ciphertext:   fields[3],
+		tag:          fields[4],
+	}
+	return nil
+}
+
+func (h *Header) unmarshal(encodedHeader string) error {
+	jsonHeader, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := json.Unmarshal(jsonHeader, h); err != nil {
+		return ErrInvalidToken
+	}
+
+	if h.Alg != RSAOAEP {
+		return unsupportedAlgorithmError{alg: h.Alg}
+	}
+
+	if h.Enc != A256GCM {
+		return unsupportedEncryptionError{enc: h.Enc}
+	}
+
+	if h.Zip != "" {
+		return unsupportedCompressionError{zip: h.Zip}
+	}
+
+	return nil
+}
+
+// Decrypt decrypts a compact JWE using RSA-OAEP key unwrapping and A256GCM
+// content decryption, returning the plaintext. For nested tokens the plaintext
+// is the inner compact JWT, which must still be verified by the caller.
+func Decrypt(jwe string, privateKey *rsa.PrivateKey) ([]byte, error) {
+	if privateKey == nil {
+		return nil, ErrInvalidKey
+	}
+
+	segs := segments{}
+
+	if err := segs.unmarshal(jwe); err != nil {
+		return nil, err
+	}
+
+	header := Header{}
+
+	if err := header.unmarshal(segs.header); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := base64.RawURLEncoding.DecodeString(segs.encryptedKey)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(segs.iv)
+	if err != nil || len(iv) != ivSize {
+		return nil, ErrInvalidToken
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(segs.ciphertext)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	tag, err := base64.RawURLEncoding.DecodeString(segs.tag)
+	if err != nil || len(tag) != tagSize {
+		return nil, ErrInvalidToken
+	}
+
+	cek, err := rsa.DecryptOAEP(sha1.New(), nil, privateKey, encryptedKey, nil)
+	if err != nil || len(cek) != cekSize {
+		return nil, ErrDecryption
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	// The additional authenticated data is the encoded protected header
+	aad := []byte(segs.header)
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), aad)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return plaintext, nil
+}
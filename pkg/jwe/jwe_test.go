@@ -0,0 +1,202 @@
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/othonhugo/gotoken/pkg/jwt"
+)
+
+// encrypt builds a compact JWE for the given protected header and plaintext
+func encrypt(t *testing.T, header string, plaintext []byte, pub *rsa.PublicKey) string {
+	t.Helper()
+
+	cek := make([]byte, cekSize)
+	iv := make([]byte, ivSize)
+
+	if _, err := rand.Read(cek); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString([]byte(header))
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+
+	return strings.Join([]string{
+		encodedHeader,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, ".")
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// TestDecryptNestedJWT verifies a nested JWT decrypts and then verifies
+func TestDecryptNestedJWT(t *testing.T) {
+	key := generateKey(t)
+	secret := []byte("test-secret")
+
+	inner, err := jwt.Marshal(jwt.Header{Alg: jwt.HS256}, jwt.Claims{Subject: "user123"}, secret)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	token := encrypt(t, `{"alg":"RSA-OAEP","enc":"A256GCM","cty":"JWT"}`, []byte(inner), &key.PublicKey)
+
+	plaintext, err := Decrypt(token, key)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+
+	if string(plaintext) != inner {
+		t.Fatalf("Decrypt() = %q, want %q", plaintext, inner)
+	}
+
+	var claims jwt.Claims
+
+	if err := jwt.Unmarshal(string(plaintext), &claims, secret); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if claims.Subject != "user123" {
+		t.Errorf("Subject = %v, want %v", claims.Subject, "user123")
+	}
+}
+
+// TestDecryptErrors verifies malformed and unsupported tokens are rejected
+func TestDecryptErrors(t *testing.T) {
+	key := generateKey(t)
+	other := generateKey(t)
+	plaintext := []byte("plaintext")
+	valid := encrypt(t, `{"alg":"RSA-OAEP","enc":"A256GCM"}`, plaintext, &key.PublicKey)
+
+	tamper := func(index int) string {
+		parts := strings.Split(valid, ".")
+		raw, _ := base64.RawURLEncoding.DecodeString(parts[index])
+		raw[0] ^= 0xff
+		parts[index] = base64.RawURLEncoding.EncodeToString(raw)
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		key     *rsa.PrivateKey
+		wantErr error
+		anyErr  bool
+	}{
+		{
+			name:    "nil key",
+			token:   valid,
+			key:     nil,
+			wantErr: ErrInvalidKey,
+		},
+		{
+			name:    "too few segments",
+			token:   "a.b.c",
+			key:     key,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "too many segments",
+			token:   valid + ".extra",
+			key:     key,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong private key",
+			token:   valid,
+			key:     other,
+			wantErr: ErrDecryption,
+		},
+		{
+			name:    "tampered ciphertext",
+			token:   tamper(3),
+			key:     key,
+			wantErr: ErrDecryption,
+		},
+		{
+			name:    "tampered tag",
+			token:   tamper(4),
+			key:     key,
+			wantErr: ErrDecryption,
+		},
+		{
+			name:    "tampered header",
+			token:   encrypt(t, `{"alg":"RSA-OAEP","enc":"A256GCM","cty":"JWT"}`, plaintext, &key.PublicKey)[1:],
+			key:     key,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:   "unsupported algorithm",
+			token:  encrypt(t, `{"alg":"RSA1_5","enc":"A256GCM"}`, plaintext, &key.PublicKey),
+			key:    key,
+			anyErr: true,
+		},
+		{
+			name:   "unsupported encryption",
+			token:  encrypt(t, `{"alg":"RSA-OAEP","enc":"A128GCM"}`, plaintext, &key.PublicKey),
+			key:    key,
+			anyErr: true,
+		},
+		{
+			name:   "unsupported compression",
+			token:  encrypt(t, `{"alg":"RSA-OAEP","enc":"A256GCM","zip":"DEF"}`, plaintext, &key.PublicKey),
+			key:    key,
+			anyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.token, tt.key)
+
+			if tt.anyErr {
+				if err == nil {
+					t.Error("Decrypt() should fail")
+				}
+				return
+			}
+
+			if err != tt.wantErr {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
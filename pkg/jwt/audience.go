@@ -0,0 +1,53 @@
+package jwt
+
+import "encoding/json"
+
+// Audience represents the 'aud' claim, which RFC 7519 allows to be either a
+// single string or an array of strings. A single audience is encoded as a
+// string, so tokens with one audience keep their compact form.
+type Audience []string
+
+// MarshalJSON encodes a single audience as a string and several as an array.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON decodes either a string or an array of strings. Any other JSON
+// value, including an array holding non-strings, fails with ErrMalformedClaims.
+// A null leaves the audience unchanged.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	// Pointers tell null elements apart from empty strings
+	var multiple []*string
+
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return ErrMalformedClaims
+	}
+
+	audience := make(Audience, 0, len(multiple))
+
+	for _, aud := range multiple {
+		if aud == nil {
+			return ErrMalformedClaims
+		}
+
+		audience = append(audience, *aud)
+	}
+
+	*a = audience
+	return nil
+}
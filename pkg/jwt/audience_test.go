@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestAudienceMarshalJSON verifies single audiences stay compact
+func TestAudienceMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		aud  Audience
+		want string
+	}{
+		{
+			name: "single audience",
+			aud:  Audience{"api"},
+			want: `"api"`,
+		},
+		{
+			name: "multiple audiences",
+			aud:  Audience{"api", "web"},
+			want: `["api","web"]`,
+		},
+		{
+			name: "empty audience",
+			aud:  Audience{},
+			want: `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.aud)
+
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAudienceUnmarshalJSON verifies both RFC 7519 audience forms decode
+func TestAudienceUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Audience
+		wantErr bool
+	}{
+		{
+			name:  "string",
+			input: `"api"`,
+			want:  Audience{"api"},
+		},
+		{
+			name:  "array",
+			input: `["api","web"]`,
+			want:  Audience{"api", "web"},
+		},
+		{
+			name:  "empty array",
+			input: `[]`,
+			want:  Audience{},
+		},
+		{
+			name:    "object",
+			input:   `{"aud":"api"}`,
+			wantErr: true,
+		},
+		{
+			name:    "number",
+			input:   `123`,
+			wantErr: true,
+		},
+		{
+			name:    "boolean",
+			input:   `true`,
+			wantErr: true,
+		},
+		{
+			name:    "array with a number",
+			input:   `["api",123]`,
+			wantErr: true,
+		},
+		{
+			name:    "array with null",
+			input:   `["api",null,"web"]`,
+			wantErr: true,
+		},
+		{
+			name:  "null",
+			input: `null`,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Audience
+
+			err := json.Unmarshal([]byte(tt.input), &got)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("json.Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("malformed audience fails Unmarshal", func(t *testing.T) {
+		secret := []byte("test-secret")
+
+		for _, aud := range []string{`123`, `["api",123]`, `{"a":"b"}`} {
+			header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+			payload := encodeJWTBase64([]byte(`{"sub":"user123","aud":` + aud + `}`))
+			signature, _ := ComputeSignature(header+"."+payload, HS256, secret)
+
+			var decoded Claims
+
+			if err := Unmarshal(header+"."+payload+"."+signature, &decoded, secret); err != ErrMalformedClaims {
+				t.Errorf("Unmarshal() with aud %s error = %v, want %v", aud, err, ErrMalformedClaims)
+			}
+		}
+	})
+
+	t.Run("omitted when empty", func(t *testing.T) {
+		got, _ := json.Marshal(Claims{Subject: "user123"})
+
+		if string(got) != `{"sub":"user123"}` {
+			t.Errorf("json.Marshal() = %s", got)
+		}
+	})
+}
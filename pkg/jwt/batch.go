@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+)
+
+// Keyfunc returns the key verifying a token with the given header, e.g. by
+// looking up its 'kid' header in a key store. It can be passed as the key to
+// Unmarshal, which calls it once the header and algorithm have been checked.
+type Keyfunc func(header Header) (any, error)
+
+func (f Keyfunc) resolveKey(h Header) (any, error) {
+	return f(h)
+}
+
+// VerifyBatchConcurrent verifies tokens like Unmarshal with keys from keyfunc,
+// spread over up to concurrency goroutines, and returns one error per token in
+// input order, nil for those that pass. The claims are discarded. Every
+// verification computes its own HMAC, so keyfunc is the only state shared
+// between goroutines and must be safe for concurrent use, as must any
+// callback given in opts.
+//
+// Once ctx is done, tokens not yet started are not verified and report the
+// context error; verifications already running complete. A nil keyfunc makes
+// every token report ErrNilKeyfunc.
+func VerifyBatchConcurrent(ctx context.Context, tokens []string, keyfunc Keyfunc, concurrency int, opts ...Option) []error {
+	errs := make([]error, len(tokens))
+
+	// A nil keyfunc would panic inside a worker, out of reach of the caller
+	if keyfunc == nil {
+		for i := range errs {
+			errs[i] = ErrNilKeyfunc
+		}
+
+		return errs
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < len(tokens); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				var claims Claims
+
+				errs[i] = Unmarshal(tokens[i], &claims, keyfunc, opts...)
+			}
+		}()
+	}
+
+	if stopped := feedJobs(ctx, jobs, len(tokens)); stopped < len(tokens) {
+		for i := stopped; i < len(tokens); i++ {
+			errs[i] = ctx.Err()
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+// feedJobs sends the indexes 0 to n-1 to jobs until ctx is done and returns
+// the first index that was not sent.
+func feedJobs(ctx context.Context, jobs chan<- int, n int) int {
+	for i := 0; i < n; i++ {
+		// A done context wins over an idle worker
+		if ctx.Err() != nil {
+			return i
+		}
+
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			return i
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// TestKeyfunc verifies Unmarshal resolves the key from the token header
+func TestKeyfunc(t *testing.T) {
+	keys := map[string][]byte{"a": []byte("secret-a"), "b": []byte("secret-b")}
+
+	keyfunc := Keyfunc(func(h Header) (any, error) {
+		if key, ok := keys[h.Kid]; ok {
+			return key, nil
+		}
+
+		return nil, ErrUnknownKeyID
+	})
+
+	token, _ := Marshal(Header{Alg: HS256, Kid: "b"}, Claims{}, keys["b"])
+	unknown, _ := Marshal(Header{Alg: HS256, Kid: "c"}, Claims{}, keys["b"])
+
+	if err := Unmarshal(token, &Claims{}, keyfunc); err != nil {
+		t.Errorf("Unmarshal() error = %v", err)
+	}
+
+	if err := Unmarshal(unknown, &Claims{}, keyfunc); err != ErrUnknownKeyID {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnknownKeyID)
+	}
+}
+
+// TestVerifyBatchConcurrent verifies results keep the input order regardless of concurrency
+func TestVerifyBatchConcurrent(t *testing.T) {
+	var tokens []string
+	var want []error
+
+	for i := 0; i < 50; i++ {
+		kid := strconv.Itoa(i % 5)
+		secret := []byte("secret-" + kid)
+
+		var wantErr error
+
+		// Every third token is signed with the wrong secret
+		if i%3 == 0 {
+			secret = []byte("other-secret")
+			wantErr = ErrSignatureMismatch
+		}
+
+		token, _ := Marshal(Header{Alg: HS256, Kid: kid}, Claims{Subject: strconv.Itoa(i)}, secret)
+		tokens = append(tokens, token)
+		want = append(want, wantErr)
+	}
+
+	tokens = append(tokens, "not-a-token")
+	want = append(want, ErrInvalidToken)
+
+	keyfunc := Keyfunc(func(h Header) (any, error) {
+		return []byte("secret-" + h.Kid), nil
+	})
+
+	for _, concurrency := range []int{-1, 1, 4, 100} {
+		t.Run("concurrency "+strconv.Itoa(concurrency), func(t *testing.T) {
+			errs := VerifyBatchConcurrent(context.Background(), tokens, keyfunc, concurrency)
+
+			if len(errs) != len(want) {
+				t.Fatalf("len(errs) = %d, want %d", len(errs), len(want))
+			}
+
+			for i := range want {
+				if errs[i] != want[i] {
+					t.Errorf("errs[%d] = %v, want %v", i, errs[i], want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("empty batch", func(t *testing.T) {
+		if errs := VerifyBatchConcurrent(context.Background(), nil, keyfunc, 4); len(errs) != 0 {
+			t.Errorf("errs = %v, want none", errs)
+		}
+	})
+
+	t.Run("nil keyfunc", func(t *testing.T) {
+		errs := VerifyBatchConcurrent(context.Background(), tokens[:3], nil, 4)
+
+		want := []error{ErrNilKeyfunc, ErrNilKeyfunc, ErrNilKeyfunc}
+
+		if !reflect.DeepEqual(errs, want) {
+			t.Errorf("errs = %v, want %v", errs, want)
+		}
+	})
+}
+
+// TestVerifyBatchConcurrentCancel verifies remaining tokens report the context error once cancelled
+func TestVerifyBatchConcurrentCancel(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := Marshal(Header{Alg: HS256}, Claims{}, secret)
+	tokens := []string{token, token, token, token, token}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+
+	keyfunc := Keyfunc(func(Header) (any, error) {
+		// Cancel after the second verification has started
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+
+		return secret, nil
+	})
+
+	errs := VerifyBatchConcurrent(ctx, tokens, keyfunc, 1)
+
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("errs[:2] = %v, want started verifications to complete", errs[:2])
+	}
+
+	for i, err := range errs[2:] {
+		if err != context.Canceled {
+			t.Errorf("errs[%d] = %v, want %v", i+2, err, context.Canceled)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("keyfunc calls = %d, want 2", n)
+	}
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import "strconv"
+
+// appendClaimsJSON appends the JSON encoding of c to dst without reflection.
+// The output is byte-identical to the generic encoder; it reports false when
+// a string would need escaping and the generic encoder must be used instead.
+func appendClaimsJSON(dst []byte, c *Claims) ([]byte, bool) {
+	for _, s := range []string{c.Issuer, c.Subject, c.ID} {
+		if !isPlainJSONString(s) {
+			return dst, false
+		}
+	}
+
+	for _, s := range c.Audience {
+		if !isPlainJSONString(s) {
+			return dst, false
+		}
+	}
+
+	dst = append(dst, '{')
+	start := len(dst)
+
+	field := func(name string) {
+		if len(dst) > start {
+			dst = append(dst, ',')
+		}
+
+		dst = append(dst, '"')
+		dst = append(dst, name...)
+		dst = append(dst, '"', ':')
+	}
+
+	str := func(s string) {
+		dst = append(dst, '"')
+		dst = append(dst, s...)
+		dst = append(dst, '"')
+	}
+
+	if c.Issuer != "" {
+		field("iss")
+		str(c.Issuer)
+	}
+
+	if c.Subject != "" {
+		field("sub")
+		str(c.Subject)
+	}
+
+	switch len(c.Audience) {
+	case 0:
+	case 1:
+		field("aud")
+		str(c.Audience[0])
+	default:
+		field("aud")
+		dst = append(dst, '[')
+
+		for i, aud := range c.Audience {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			str(aud)
+		}
+
+		dst = append(dst, ']')
+	}
+
+	for _, date := range []struct {
+		name  string
+		value int64
+	}{
+		{"exp", c.ExpiresAt},
+		{"nbf", c.NotBefore},
+		{"iat", c.IssuedAt},
+	} {
+		if date.value != 0 {
+			field(date.name)
+			dst = strconv.AppendInt(dst, date.value, 10)
+		}
+	}
+
+	if c.ID != "" {
+		field("jti")
+		str(c.ID)
+	}
+
+	return append(dst, '}'), true
+}
+
+// isPlainJSONString reports whether s encodes as itself between quotes, which
+// holds for printable ASCII other than the quote and the backslash.
+func isPlainJSONString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,17 @@
+package jwt
+
+import "net/http"
+
+// FromCookie decodes and validates the JWT held in the request cookie called
+// name like Unmarshal. A request without that cookie, or with an empty one,
+// returns ErrMissingCookie; errors from verifying the token itself are
+// returned unchanged.
+func FromCookie(r *http.Request, name string, claims any, secret []byte, opts ...Option) error {
+	cookie, err := r.Cookie(name)
+
+	if err != nil || cookie.Value == "" {
+		return ErrMissingCookie
+	}
+
+	return Unmarshal(cookie.Value, claims, secret, opts...)
+}
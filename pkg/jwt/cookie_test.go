@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFromCookie verifies tokens are read from the named cookie and then verified
+func TestFromCookie(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+	forged, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, []byte("other-secret"))
+
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+		wantErr error
+	}{
+		{
+			name:    "session cookie",
+			cookies: []*http.Cookie{{Name: "theme", Value: "dark"}, {Name: "session", Value: token}},
+			wantErr: nil,
+		},
+		{
+			name:    "missing cookie",
+			cookies: []*http.Cookie{{Name: "theme", Value: "dark"}},
+			wantErr: ErrMissingCookie,
+		},
+		{
+			name:    "empty cookie",
+			cookies: []*http.Cookie{{Name: "session", Value: ""}},
+			wantErr: ErrMissingCookie,
+		},
+		{
+			name:    "invalid token",
+			cookies: []*http.Cookie{{Name: "session", Value: "not-a-token"}},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "forged token",
+			cookies: []*http.Cookie{{Name: "session", Value: forged}},
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			for _, c := range tt.cookies {
+				r.AddCookie(c)
+			}
+
+			var decoded Claims
+
+			err := FromCookie(r, "session", &decoded, secret)
+
+			if err != tt.wantErr {
+				t.Fatalf("FromCookie() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && decoded.Subject != "user123" {
+				t.Errorf("Subject = %q, want %q", decoded.Subject, "user123")
+			}
+		})
+	}
+}
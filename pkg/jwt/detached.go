@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"io"
+)
+
+// VerifyDetachedReader verifies a token with detached content (RFC 7515
+// appendix F), whose compact form "header..signature" leaves the payload
+// segment empty, against the content read from payload. The content is
+// base64url-encoded and fed to the HMAC as it streams, so files of any size
+// are verified in constant memory.
+//
+// Only the header and signature are checked; the content is opaque and its
+// 'typ' header is not restricted. A token carrying its payload inline returns
+// ErrInvalidToken, and errors reading payload are returned verbatim.
+func VerifyDetachedReader(jws string, payload io.Reader, secret []byte) error {
+	return VerifyDetachedReaderContext(context.Background(), jws, payload, secret)
+}
+
+// VerifyDetachedReaderContext is VerifyDetachedReader stopping with the
+// context error once ctx is done, checked before each read from payload.
+func VerifyDetachedReaderContext(ctx context.Context, jws string, payload io.Reader, secret []byte) error {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return err
+	}
+
+	if b64vals.header == "" || b64vals.payload != "" || !isBase64URL(b64vals.header) {
+		return ErrInvalidToken
+	}
+
+	var header Header
+
+	if err := header.unmarshal(b64vals.header); err != nil {
+		return err
+	}
+
+	method, err := methodFor(header.Alg, secret)
+
+	if err != nil {
+		return err
+	}
+
+	expected, err := decodeJWTBase64(b64vals.signature)
+
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := method.checkLength(len(expected)); err != nil {
+		return err
+	}
+
+	mac, err := header.signer(secret)
+
+	if err != nil {
+		return err
+	}
+
+	mac.Write([]byte(b64vals.header + "."))
+
+	content := base64.NewEncoder(base64.RawURLEncoding, mac)
+
+	if _, err := io.Copy(content, contextReader{ctx: ctx, r: payload}); err != nil {
+		return err
+	}
+
+	// Close flushes the final partial base64 quantum into the HMAC
+	if err := content.Close(); err != nil {
+		return err
+	}
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// contextReader fails reads with the context error once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// detachedToken signs content and returns the token with its payload segment removed
+func detachedToken(header string, content []byte, secret []byte) string {
+	encodedHeader := encodeJWTBase64([]byte(header))
+	signature, _ := ComputeSignature(encodedHeader+"."+encodeJWTBase64(content), HS256, secret)
+
+	return encodedHeader + ".." + signature
+}
+
+// TestVerifyDetachedReader verifies detached content is checked against the streamed payload
+func TestVerifyDetachedReader(t *testing.T) {
+	secret := []byte("test-secret")
+	content := bytes.Repeat([]byte("large file contents\n"), 10000)
+	token := detachedToken(`{"alg":"HS256"}`, content, secret)
+	attached, _ := Marshal(Header{Alg: HS256}, Claims{}, secret)
+
+	tests := []struct {
+		name    string
+		jws     string
+		payload []byte
+		secret  []byte
+		wantErr error
+	}{
+		{
+			name:    "matching content",
+			jws:     token,
+			payload: content,
+			secret:  secret,
+			wantErr: nil,
+		},
+		{
+			name:    "empty content",
+			jws:     detachedToken(`{"alg":"HS256"}`, nil, secret),
+			payload: nil,
+			secret:  secret,
+			wantErr: nil,
+		},
+		{
+			name:    "tampered content",
+			jws:     token,
+			payload: append(append([]byte(nil), content...), '!'),
+			secret:  secret,
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "wrong secret",
+			jws:     token,
+			payload: content,
+			secret:  []byte("other-secret"),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "attached payload",
+			jws:     attached,
+			payload: content,
+			secret:  secret,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "unsupported algorithm",
+			jws:     encodeJWTBase64([]byte(`{"alg":"none"}`)) + "..",
+			payload: content,
+			secret:  secret,
+			wantErr: unsupportedAlgorithmError{alg: "none"},
+		},
+		{
+			name:    "truncated signature",
+			jws:     token[:len(token)-4],
+			payload: content,
+			secret:  secret,
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reading in small chunks splits the content across base64 groups
+			payload := iotest.HalfReader(bytes.NewReader(tt.payload))
+
+			if err := VerifyDetachedReader(tt.jws, payload, tt.secret); err != tt.wantErr {
+				t.Errorf("VerifyDetachedReader() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestVerifyDetachedReaderLength verifies signatures of the wrong length are rejected before reading
+func TestVerifyDetachedReaderLength(t *testing.T) {
+	secret := []byte("test-secret")
+	token := detachedToken(`{"alg":"HS256"}`, []byte("contents"), secret)
+
+	payload := iotest.ErrReader(errors.New("payload read"))
+
+	if err := VerifyDetachedReader(token[:len(token)-4], payload, secret); err != ErrSignatureMismatch {
+		t.Errorf("VerifyDetachedReader() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+// TestVerifyDetachedReaderContext verifies verification stops once the context is done
+func TestVerifyDetachedReaderContext(t *testing.T) {
+	secret := []byte("test-secret")
+	content := []byte("contents")
+	token := detachedToken(`{"alg":"HS256"}`, content, secret)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := VerifyDetachedReaderContext(ctx, token, bytes.NewReader(content), secret); err != context.Canceled {
+		t.Errorf("VerifyDetachedReaderContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	errRead := errors.New("read failed")
+
+	if err := VerifyDetachedReader(token, io.MultiReader(strings.NewReader("con"), iotest.ErrReader(errRead)), secret); err != errRead {
+		t.Errorf("VerifyDetachedReader() error = %v, want %v", err, errRead)
+	}
+}
@@ -0,0 +1,138 @@
+package jwt
+
+// Diagnose checks a token like Unmarshal but, instead of stopping at the first
+// failure, reports every one it finds: structure, 'typ', algorithm, signature,
+// time claims, and each validator given in opts. Checks that depend on a
+// readable header or payload are skipped when those cannot be decoded. It is
+// meant for diagnostic tools explaining why a token is rejected; its result
+// must never be used to accept a token.
+func Diagnose(jws string, key any, opts ...Option) (Header, []error) {
+	o := newOptions(opts)
+
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return Header{}, []error{err}
+	}
+
+	if o.lenientBase64 {
+		b64vals = b64vals.normalize()
+	}
+
+	if o.lenientSigB64 {
+		b64vals.signature = canonicalBase64(b64vals.signature)
+	}
+
+	t := &token{
+		payload:        payload{claims: &map[string]any{}},
+		raw:            b64vals,
+		signingContext: o.signingContext,
+	}
+
+	if b64vals.header == "" || !isBase64URL(b64vals.header) {
+		return Header{}, []error{ErrInvalidToken}
+	}
+
+	if err := t.header.decode(b64vals.header, o.strictHeaders); err != nil {
+		return Header{}, []error{err}
+	}
+
+	var errs []error
+
+	if o.requireKid && t.header.Kid == "" {
+		errs = append(errs, ErrMissingKeyID)
+	}
+
+	for _, check := range o.headerChecks {
+		if err := check(t.header); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !o.allowsType(t.header.Typ) {
+		errs = append(errs, unsupportedTypeError{typ: t.header.Typ})
+	}
+
+	verifier, err := o.verificationHeader(t.header)
+
+	// A rejected header is still checked against the forced algorithm
+	if err != nil {
+		errs = append(errs, err)
+		verifier = t.header
+		verifier.Alg = o.forcedAlg
+	}
+
+	if !o.allowsAlgorithm(verifier.Alg) {
+		errs = append(errs, ErrUnexpectedAlgorithm)
+	}
+
+	if err := t.verifySignature(verifier, key, o); err != nil {
+		errs = append(errs, err)
+	}
+
+	if b64vals.payload == "" {
+		return t.header, append(errs, ErrInvalidToken)
+	}
+
+	t.payload.stringyDates = o.stringyDates
+	t.payload.cipher = o.claimCipher
+
+	if err := t.payload.unmarshal(b64vals.payload); err != nil {
+		return t.header, append(errs, err)
+	}
+
+	registered, err := t.payload.registeredClaims()
+
+	if err != nil {
+		return t.header, append(errs, err)
+	}
+
+	if !o.allowExpired && o.zeroExpiry(t) {
+		errs = append(errs, ErrTokenExpired)
+	}
+
+	errs = append(errs, registered.violations(o)...)
+
+	if err := o.checkAudienceCount(t); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, v := range o.validators {
+		if err := v(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return t.header, errs
+}
+
+// verifySignature reports why the signature of the raw segments does not verify
+// against the algorithm of verifier, checking the key in the same order as
+// Unmarshal.
+func (t *token) verifySignature(verifier Header, key any, o *options) error {
+	method, err := o.verificationMethod(verifier.Alg, key)
+
+	if err != nil {
+		return err
+	}
+
+	expected, err := decodeJWTBase64(t.raw.signature)
+
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := method.checkLength(len(expected)); err != nil {
+		return err
+	}
+
+	key, err = resolveKey(key, verifier)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = verifyCandidates(method, []byte(t.signingInput(t.raw.header, t.raw.payload)), expected, key)
+
+	return err
+}
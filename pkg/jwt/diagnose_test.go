@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestDiagnose verifies every failure of a token is reported at once
+func TestDiagnose(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	claims := Claims{
+		Issuer:    "other-issuer",
+		Audience:  Audience{"web"},
+		ExpiresAt: now.Add(-time.Hour).Unix(),
+		NotBefore: now.Add(time.Hour).Unix(),
+	}
+
+	token, _ := Marshal(Header{Alg: HS256}, claims, secret)
+
+	opts := []Option{
+		WithNow(now),
+		WithIssuer("issuer"),
+		WithAudience("api"),
+		WithRequiredClaims("jti"),
+	}
+
+	tests := []struct {
+		name string
+		key  any
+		want []error
+	}{
+		{
+			name: "all claim failures",
+			key:  secret,
+			want: []error{ErrTokenExpired, ErrTokenNotValidYet, ErrInvalidIssuer, ErrInvalidAudience, ErrMissingClaim},
+		},
+		{
+			name: "signature failure is included",
+			key:  []byte("wrong"),
+			want: []error{ErrSignatureMismatch, ErrTokenExpired, ErrTokenNotValidYet, ErrInvalidIssuer, ErrInvalidAudience, ErrMissingClaim},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, errs := Diagnose(token, tt.key, opts...)
+
+			if header.Alg != HS256 {
+				t.Errorf("Alg = %v, want %v", header.Alg, HS256)
+			}
+
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("Diagnose() = %v, want %v", errs, tt.want)
+			}
+		})
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{Issuer: "issuer"}, secret)
+
+		if _, errs := Diagnose(token, secret, WithIssuer("issuer")); len(errs) != 0 {
+			t.Errorf("Diagnose() = %v, want no errors", errs)
+		}
+	})
+
+	t.Run("key set and asymmetric keys", func(t *testing.T) {
+		keys := SymmetricKeySet{"current": secret}
+
+		token, _ := keys.Marshal(Header{Alg: HS256}, Claims{}, "current")
+
+		if _, errs := Diagnose(token, keys); len(errs) != 0 {
+			t.Errorf("Diagnose() with a key set = %v, want no errors", errs)
+		}
+
+		public, private, _ := ed25519.GenerateKey(rand.Reader)
+
+		token, _ = Marshal(Header{Alg: EdDSA}, Claims{}, private)
+
+		if _, errs := Diagnose(token, public); len(errs) != 0 {
+			t.Errorf("Diagnose() with an Ed25519 key = %v, want no errors", errs)
+		}
+
+		if _, errs := Diagnose(token, secret); !reflect.DeepEqual(errs, []error{ErrAlgorithmMismatch}) {
+			t.Errorf("Diagnose() with a secret = %v, want [%v]", errs, ErrAlgorithmMismatch)
+		}
+	})
+
+	t.Run("header problems", func(t *testing.T) {
+		header := encodeJWTBase64([]byte(`{"alg":"HS384","typ":"at+jwt"}`))
+		payload := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+		signature, _ := ComputeSignature(header+"."+payload, HS384, secret)
+
+		_, errs := Diagnose(header+"."+payload+"."+signature, secret, WithAllowedAlgorithms(HS256))
+
+		want := []error{unsupportedTypeError{typ: "at+jwt"}, ErrUnexpectedAlgorithm}
+
+		if !reflect.DeepEqual(errs, want) {
+			t.Errorf("Diagnose() = %v, want %v", errs, want)
+		}
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		if _, errs := Diagnose("not-a-token", secret); !reflect.DeepEqual(errs, []error{ErrInvalidToken}) {
+			t.Errorf("Diagnose() = %v, want [%v]", errs, ErrInvalidToken)
+		}
+	})
+}
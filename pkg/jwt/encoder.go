@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"hash"
+	"io"
+)
+
+// Encoder streams a signed JWT to an io.Writer, for payloads too large to
+// build in memory. The payload JSON is written incrementally with Write; it is
+// base64url-encoded and fed to the HMAC as it streams, and Close appends the
+// signature.
+//
+// Memory use is constant regardless of the payload size: the encoder only
+// holds the keyed hash state and base64's pending partial 3-byte group. The
+// token is only complete once Close returns nil; until then w holds a prefix
+// that must be discarded on error.
+//
+// Unlike Marshal, the encoder neither validates that the payload is JSON nor
+// applies options such as WithAutoIssuedAt; the caller writes the exact claims
+// document.
+type Encoder struct {
+	w       io.Writer
+	mac     hash.Hash
+	payload io.WriteCloser
+	closed  bool
+	err     error
+}
+
+// NewEncoder writes the encoded header of a token to w and returns an Encoder
+// for its payload. An empty 'typ' defaults to JWT as with Marshal.
+func NewEncoder(w io.Writer, header Header, secret []byte) (*Encoder, error) {
+	if header.Typ == "" {
+		header.Typ = JWT
+	}
+
+	mac, err := header.signer(secret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tokenHeader, err := header.marshal()
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := []byte(tokenHeader + ".")
+
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	mac.Write(prefix)
+
+	return &Encoder{
+		w:       w,
+		mac:     mac,
+		payload: base64.NewEncoder(base64.RawURLEncoding, io.MultiWriter(w, mac)),
+	}, nil
+}
+
+// Write writes the next chunk of the payload JSON. Chunks may split the
+// document anywhere.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrEncoderClosed
+	}
+
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	n, err := e.payload.Write(p)
+	e.err = err
+
+	return n, err
+}
+
+// Close flushes the payload and writes the signature, completing the token.
+// Calling Close again returns ErrEncoderClosed.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+
+	e.closed = true
+
+	if e.err != nil {
+		return e.err
+	}
+
+	if err := e.payload.Close(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(e.w, "."+encodeJWTBase64(e.mac.Sum(nil)))
+
+	return err
+}
@@ -0,0 +1,143 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestEncoder verifies streamed tokens match Marshal whatever the chunking
+func TestEncoder(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS512}
+	document := `{"sub":"user123","manifest":"` + strings.Repeat("0123456789", 1000) + `"}`
+
+	want, err := Marshal(header, json.RawMessage(document), secret)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, chunk := range []int{1, 2, 3, 4, 7, 4096, len(document)} {
+		var buf bytes.Buffer
+
+		enc, err := NewEncoder(&buf, header, secret)
+
+		if err != nil {
+			t.Fatalf("NewEncoder() error = %v", err)
+		}
+
+		for rest := document; rest != ""; {
+			n := chunk
+
+			if n > len(rest) {
+				n = len(rest)
+			}
+
+			if _, err := enc.Write([]byte(rest[:n])); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			rest = rest[n:]
+		}
+
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if got := buf.String(); got != want {
+			t.Errorf("chunk %d: streamed token differs from Marshal", chunk)
+		}
+	}
+}
+
+// TestEncoderVerifies verifies streamed tokens round-trip through Unmarshal
+func TestEncoderVerifies(t *testing.T) {
+	secret := []byte("test-secret")
+
+	var buf bytes.Buffer
+
+	enc, _ := NewEncoder(&buf, Header{Alg: HS256}, secret)
+	enc.Write([]byte(`{"sub":`))
+	enc.Write([]byte(`"user123"}`))
+
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var claims Claims
+
+	if err := Unmarshal(buf.String(), &claims, secret); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if claims.Subject != "user123" {
+		t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+	}
+}
+
+// failingWriter fails every write after the first n bytes
+type failingWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return 0, errWrite
+	}
+
+	w.n -= len(p)
+
+	return len(p), nil
+}
+
+// TestEncoderErrors verifies invalid headers, write failures, and reuse are reported
+func TestEncoderErrors(t *testing.T) {
+	secret := []byte("test-secret")
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		if _, err := NewEncoder(&bytes.Buffer{}, Header{Alg: "none"}, secret); err == nil {
+			t.Error("NewEncoder() error = nil, want an unsupported algorithm error")
+		}
+	})
+
+	t.Run("header write failure", func(t *testing.T) {
+		if _, err := NewEncoder(&failingWriter{}, Header{Alg: HS256}, secret); err != errWrite {
+			t.Errorf("NewEncoder() error = %v, want %v", err, errWrite)
+		}
+	})
+
+	t.Run("payload write failure is sticky", func(t *testing.T) {
+		enc, err := NewEncoder(&failingWriter{n: 64}, Header{Alg: HS256}, secret)
+
+		if err != nil {
+			t.Fatalf("NewEncoder() error = %v", err)
+		}
+
+		if _, err := enc.Write(bytes.Repeat([]byte("a"), 128)); err != errWrite {
+			t.Fatalf("Write() error = %v, want %v", err, errWrite)
+		}
+
+		if err := enc.Close(); err != errWrite {
+			t.Errorf("Close() error = %v, want %v", err, errWrite)
+		}
+	})
+
+	t.Run("closed encoder", func(t *testing.T) {
+		enc, _ := NewEncoder(&bytes.Buffer{}, Header{Alg: HS256}, secret)
+		enc.Write([]byte(`{}`))
+		enc.Close()
+
+		if _, err := enc.Write([]byte(`{}`)); err != ErrEncoderClosed {
+			t.Errorf("Write() error = %v, want %v", err, ErrEncoderClosed)
+		}
+
+		if err := enc.Close(); err != ErrEncoderClosed {
+			t.Errorf("Close() error = %v, want %v", err, ErrEncoderClosed)
+		}
+	})
+}
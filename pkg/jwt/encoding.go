@@ -1,8 +1,10 @@
 package jwt
 
 import (
+	"bytes"
 	"encoding/base64"
 	"strings"
+	"sync"
 )
 
 type b64values struct {
@@ -19,6 +21,8 @@ func (v *b64values) unmarshal(s string) error {
 		return ErrInvalidToken
 	}
 
+	// SplitN leaves any extra dots in the signature, which then fails
+	// isBase64URL, so the "header.payload" signing input is unambiguous
 	*v = b64values{
 		header:    fields[0],
 		payload:   fields[1],
@@ -27,10 +31,130 @@ func (v *b64values) unmarshal(s string) error {
 	return nil
 }
 
+// base64URLReplacer translates the standard base64 alphabet to base64url
+var base64URLReplacer = strings.NewReplacer("+", "-", "/", "_")
+
+// normalize returns the segments in canonical unpadded base64url form, with
+// trailing padding stripped and the standard alphabet translated.
+func (v b64values) normalize() b64values {
+	return b64values{
+		header:    canonicalBase64(v.header),
+		payload:   canonicalBase64(v.payload),
+		signature: canonicalBase64(v.signature),
+	}
+}
+
+// canonicalBase64 strips trailing padding from a standard or base64url segment
+// and translates it to the base64url alphabet.
+func canonicalBase64(s string) string {
+	return base64URLReplacer.Replace(strings.TrimRight(s, "="))
+}
+
+// base64Encode and base64Decode encode and decode token segments; nil means
+// the standard library's base64.RawURLEncoding.
+var (
+	base64Encode func([]byte) string
+	base64Decode func(string) ([]byte, error)
+)
+
+// SetBase64Codec replaces the base64 implementation used to encode and decode
+// the header, payload, and signature segments, e.g. with a SIMD-accelerated
+// one. Both functions must implement unpadded base64url (RFC 4648 section 5)
+// exactly as base64.RawURLEncoding does; segments outside that alphabet are
+// still rejected before decode is called. Passing nil for both restores the
+// standard library. The Encoder and VerifyDetachedReader stream through the
+// standard library regardless.
+//
+// SetBase64Codec is not safe for concurrent use with any other function of
+// this package; call it once during program initialization.
+func SetBase64Codec(encode func([]byte) string, decode func(string) ([]byte, error)) {
+	base64Encode = encode
+	base64Decode = decode
+}
+
 func encodeJWTBase64(plaintext []byte) string {
+	if base64Encode != nil {
+		return base64Encode(plaintext)
+	}
+
 	return base64.RawURLEncoding.EncodeToString(plaintext)
 }
 
+// appendJWTBase64 appends the unpadded base64url encoding of plaintext to dst.
+func appendJWTBase64(dst, plaintext []byte) []byte {
+	if base64Encode != nil {
+		return append(dst, base64Encode(plaintext)...)
+	}
+
+	n := base64.RawURLEncoding.EncodedLen(len(plaintext))
+
+	if cap(dst)-len(dst) < n {
+		grown := make([]byte, len(dst), 2*cap(dst)+n)
+		copy(grown, dst)
+		dst = grown
+	}
+
+	base64.RawURLEncoding.Encode(dst[len(dst):len(dst)+n], plaintext)
+
+	return dst[:len(dst)+n]
+}
+
+// jsonBufferPool recycles the buffers claims are JSON-encoded into before
+// being base64url-encoded.
+var jsonBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledJSONBuffer keeps buffers grown by unusually large claims out of the
+// pool, so they do not stay allocated for the life of the process.
+const maxPooledJSONBuffer = 64 << 10
+
+func getJSONBuffer() *bytes.Buffer {
+	buf := jsonBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
+}
+
+func putJSONBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledJSONBuffer {
+		jsonBufferPool.Put(buf)
+	}
+}
+
+// decodeJWTBase64 decodes a token segment, returning ErrInvalidToken for any
+// segment outside the unpadded base64url alphabet. The alphabet check rejects
+// control characters such as CR and LF, which the stdlib decoder would skip.
 func decodeJWTBase64(encoded string) ([]byte, error) {
-	return base64.RawURLEncoding.DecodeString(encoded)
+	if !isBase64URL(encoded) {
+		return nil, ErrInvalidToken
+	}
+
+	decode := base64.RawURLEncoding.DecodeString
+
+	if base64Decode != nil {
+		decode = base64Decode
+	}
+
+	decoded, err := decode(encoded)
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return decoded, nil
+}
+
+func isBase64URL(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
 }
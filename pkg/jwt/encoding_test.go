@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -130,6 +134,105 @@ func TestDecodeJWTBase64(t *testing.T) {
 	}
 }
 
+// TestDecodeJWTBase64ControlCharacters verifies characters outside the alphabet are rejected
+func TestDecodeJWTBase64ControlCharacters(t *testing.T) {
+	inputs := []string{
+		"aGVs\x00bG8",
+		"aGVs\nbG8",
+		"aGVs\rbG8",
+		"\taGVsbG8",
+		"aGVsbG8\x7f",
+		"aGVs bG8",
+		"aGVs+bG8",
+		"aGVs/bG8",
+		"aGVsbG8=",
+		"aGVsbG8.",
+		"a", // impossible length for unpadded base64
+	}
+
+	for _, input := range inputs {
+		t.Run(strconv.Quote(input), func(t *testing.T) {
+			if _, err := decodeJWTBase64(input); err != ErrInvalidToken {
+				t.Errorf("decodeJWTBase64(%q) error = %v, want %v", input, err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+// TestUnmarshalControlCharactersInSegments verifies control characters in any segment yield ErrInvalidToken
+func TestUnmarshalControlCharactersInSegments(t *testing.T) {
+	secret := []byte("secret")
+
+	token, err := Marshal(Header{Alg: HS256}, Claims{Subject: "test"}, secret)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	controls := []string{"\x00", "\n", "\r", "\x1b"}
+
+	for segment := 0; segment < 3; segment++ {
+		for _, control := range controls {
+			parts := strings.Split(token, ".")
+			parts[segment] = parts[segment][:4] + control + parts[segment][4:]
+			injected := strings.Join(parts, ".")
+
+			t.Run(fmt.Sprintf("segment %d %q", segment, control), func(t *testing.T) {
+				var decoded Claims
+
+				if err := Unmarshal(injected, &decoded, secret); err != ErrInvalidToken {
+					t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidToken)
+				}
+			})
+		}
+	}
+}
+
+// TestSetBase64Codec verifies a custom codec is used for the segments and meets the encoding tests
+func TestSetBase64Codec(t *testing.T) {
+	var encodes, decodes int
+
+	SetBase64Codec(func(b []byte) string {
+		encodes++
+		return base64.RawURLEncoding.EncodeToString(b)
+	}, func(s string) ([]byte, error) {
+		decodes++
+		return base64.RawURLEncoding.DecodeString(s)
+	})
+
+	t.Cleanup(func() { SetBase64Codec(nil, nil) })
+
+	t.Run("encode", TestEncodeJWTBase64)
+	t.Run("decode", TestDecodeJWTBase64)
+	t.Run("control characters", TestDecodeJWTBase64ControlCharacters)
+
+	secret := []byte("test-secret")
+	encodes, decodes = 0, 0
+
+	token, err := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if err := Unmarshal(token, &Claims{}, secret); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if encodes != 3 || decodes != 3 {
+		t.Errorf("codec calls = %d encodes and %d decodes, want 3 each", encodes, decodes)
+	}
+
+	t.Run("decode errors", func(t *testing.T) {
+		SetBase64Codec(nil, func(string) ([]byte, error) {
+			return nil, errors.New("codec failure")
+		})
+
+		if err := Unmarshal(token, &Claims{}, secret); err != ErrInvalidToken {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+}
+
 // TestB64ValuesRoundTrip verifies encoding and decoding work together
 func TestB64ValuesRoundTrip(t *testing.T) {
 	original := b64values{
@@ -306,6 +409,57 @@ func TestB64ValuesPreservesDots(t *testing.T) {
 	}
 }
 
+// TestB64ValuesSegmentationIsUnambiguous verifies extra dots can never shift the signing input
+func TestB64ValuesSegmentationIsUnambiguous(t *testing.T) {
+	inputs := []string{
+		"a.b.c",
+		"a.b.c.d",
+		"a.b..",
+		"...",
+	}
+
+	for _, input := range inputs {
+		var v b64values
+
+		if err := v.unmarshal(input); err != nil {
+			t.Fatalf("unmarshal(%q) error = %v", input, err)
+		}
+
+		if strings.Contains(v.header, ".") || strings.Contains(v.payload, ".") {
+			t.Errorf("unmarshal(%q) = %+v, header and payload must not contain dots", input, v)
+		}
+	}
+
+	t.Run("signature absorbing a payload is rejected", func(t *testing.T) {
+		secret := []byte("secret")
+
+		token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "test"}, secret)
+		parts := strings.Split(token, ".")
+
+		// Re-sign header+"."+payload+"."+payload so that an alternate split
+		// of the same bytes would carry a valid signature
+		extended := parts[0] + "." + parts[1] + "." + parts[1]
+		signature, _ := ComputeSignature(extended, HS256, secret)
+
+		var v b64values
+
+		if err := v.unmarshal(extended + "." + signature); err != nil {
+			t.Fatalf("unmarshal() error = %v", err)
+		}
+
+		// The extra segment lands in the signature, which is not base64url
+		if v.payload != parts[1] || isBase64URL(v.signature) {
+			t.Errorf("unmarshal() = %+v, want the extra segment in a signature that fails isBase64URL", v)
+		}
+
+		var decoded Claims
+
+		if err := Unmarshal(extended+"."+signature, &decoded, secret); err != ErrInvalidToken {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+}
+
 // BenchmarkEncodeJWTBase64 benchmarks encoding performance
 func BenchmarkEncodeJWTBase64(b *testing.B) {
 	data := []byte("The quick brown fox jumps over the lazy dog")
@@ -0,0 +1,143 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+)
+
+// claimCipher encrypts and decrypts the values of selected claims.
+type claimCipher struct {
+	key   []byte
+	names claimNames
+}
+
+// WithEncryptedClaims makes Marshal encrypt the values of the named claims
+// with AES-GCM under key, which must be 16, 24, or 32 bytes long, and Unmarshal
+// decrypt them again, so that intermediaries can read the other claims but not
+// these. Each value is replaced by the base64url encoding of a random nonce
+// followed by the sealed JSON value, bound to its claim name; the signature
+// covers the encrypted payload as usual.
+//
+// The format is specific to this package and not a standard, so such tokens
+// only interoperate with verifiers using this option. Unmarshal returns
+// ErrClaimDecryption for a named claim that does not decrypt under key, and
+// leaves absent and null claims as they are. Rewrite decrypts the named claims
+// for mutate and encrypts them again.
+func WithEncryptedClaims(key []byte, names ...string) Option {
+	return func(o *options) {
+		o.claimCipher = &claimCipher{key: key, names: names}
+	}
+}
+
+func (c *claimCipher) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the claims as a JSON object with the named claims that are
+// present and not null encrypted, keeping the member order.
+func (c *claimCipher) encrypt(claims any) (json.RawMessage, error) {
+	aead, err := c.aead()
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := encodeValue(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := decodeMembers(encoded)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range members {
+		if !c.names.has(m.name) || string(m.value) == "null" {
+			continue
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+
+		sealed := aead.Seal(nonce, nonce, m.value, []byte(m.name))
+
+		members[i].value, _ = json.Marshal(base64.RawURLEncoding.EncodeToString(sealed))
+	}
+
+	return joinMembers(members)
+}
+
+// decrypt returns the JSON payload with the named claims decrypted, keeping
+// the member order. Null claims are left as they are, since encrypt does not
+// seal them. Payloads that are not JSON objects are returned as-is, leaving
+// the error to the regular decoding.
+func (c *claimCipher) decrypt(data []byte) ([]byte, error) {
+	members, err := decodeMembers(data)
+
+	if err != nil {
+		return data, nil
+	}
+
+	aead, err := c.aead()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range members {
+		if !c.names.has(m.name) || string(m.value) == "null" {
+			continue
+		}
+
+		var encoded string
+
+		if err := json.Unmarshal(m.value, &encoded); err != nil {
+			return nil, ErrClaimDecryption
+		}
+
+		sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return nil, ErrClaimDecryption
+		}
+
+		value, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(m.name))
+
+		if err != nil || !json.Valid(value) {
+			return nil, ErrClaimDecryption
+		}
+
+		members[i].value = value
+	}
+
+	return joinMembers(members)
+}
+
+// claimNames is the set of claims a claimCipher encrypts.
+type claimNames []string
+
+func (n claimNames) has(name string) bool {
+	for _, s := range n {
+		if s == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type piiClaims struct {
+	Claims
+	SSN     string            `json:"ssn,omitempty"`
+	Address map[string]string `json:"address,omitempty"`
+}
+
+// TestWithEncryptedClaims verifies the named claims are hidden in the token and recovered by Unmarshal
+func TestWithEncryptedClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	claims := piiClaims{
+		Claims:  Claims{Subject: "user123"},
+		SSN:     "078-05-1120",
+		Address: map[string]string{"city": "Springfield"},
+	}
+
+	token, err := Marshal(Header{Alg: HS256}, claims, secret, WithEncryptedClaims(key, "ssn", "address"))
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var visible map[string]any
+
+	if _, err := ParseUnverified(token, &visible); err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+
+	if visible["sub"] != "user123" {
+		t.Errorf("sub = %v, want %q", visible["sub"], "user123")
+	}
+
+	for _, name := range []string{"ssn", "address"} {
+		if s, ok := visible[name].(string); !ok || strings.Contains(s, "078-05-1120") || strings.Contains(s, "Springfield") {
+			t.Errorf("%s = %v, want an encrypted string", name, visible[name])
+		}
+	}
+
+	var decoded piiClaims
+
+	if err := Unmarshal(token, &decoded, secret, WithEncryptedClaims(key, "ssn", "address")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.Subject != "user123" || decoded.SSN != claims.SSN || decoded.Address["city"] != "Springfield" {
+		t.Errorf("decoded = %+v, want %+v", decoded, claims)
+	}
+
+	t.Run("absent claims stay absent", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret, WithEncryptedClaims(key, "ssn"))
+
+		var decoded piiClaims
+
+		if err := Unmarshal(token, &decoded, secret, WithEncryptedClaims(key, "ssn")); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.SSN != "" {
+			t.Errorf("SSN = %q, want empty", decoded.SSN)
+		}
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: HS256}, claims, secret, WithEncryptedClaims([]byte("short"), "ssn")); err != aes.KeySizeError(5) {
+			t.Errorf("Marshal() error = %v, want %v", err, aes.KeySizeError(5))
+		}
+	})
+}
+
+// TestWithEncryptedClaimsDecryption verifies claims that do not decrypt are rejected
+func TestWithEncryptedClaimsDecryption(t *testing.T) {
+	secret := []byte("test-secret")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	claims := piiClaims{Claims: Claims{Subject: "user123"}, SSN: "078-05-1120"}
+
+	encrypted, _ := Marshal(Header{Alg: HS256}, claims, secret, WithEncryptedClaims(key, "ssn"))
+
+	var visible map[string]json.RawMessage
+
+	_, _ = ParseUnverified(encrypted, &visible)
+
+	// resign builds a validly signed token from the given payload fields
+	resign := func(fields map[string]json.RawMessage) string {
+		data, _ := json.Marshal(fields)
+		header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		payload := encodeJWTBase64(data)
+		signature, _ := ComputeSignature(header+"."+payload, HS256, secret)
+
+		return header + "." + payload + "." + signature
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		key   []byte
+		names []string
+	}{
+		{
+			name:  "wrong key",
+			token: encrypted,
+			key:   []byte("fedcba9876543210fedcba9876543210"),
+			names: []string{"ssn"},
+		},
+		{
+			name:  "plaintext claim",
+			token: resign(map[string]json.RawMessage{"ssn": json.RawMessage(`"078-05-1120"`)}),
+			key:   key,
+			names: []string{"ssn"},
+		},
+		{
+			name:  "non-string claim",
+			token: resign(map[string]json.RawMessage{"ssn": json.RawMessage(`78051120`)}),
+			key:   key,
+			names: []string{"ssn"},
+		},
+		{
+			name:  "claim moved to another name",
+			token: resign(map[string]json.RawMessage{"tax_id": visible["ssn"]}),
+			key:   key,
+			names: []string{"tax_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded map[string]any
+
+			if err := Unmarshal(tt.token, &decoded, secret, WithEncryptedClaims(tt.key, tt.names...)); err != ErrClaimDecryption {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrClaimDecryption)
+			}
+		})
+	}
+
+	t.Run("without the option the ciphertext is returned", func(t *testing.T) {
+		var decoded piiClaims
+
+		if err := Unmarshal(encrypted, &decoded, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.SSN == claims.SSN || decoded.SSN == "" {
+			t.Errorf("SSN = %q, want the ciphertext", decoded.SSN)
+		}
+	})
+}
+
+// TestWithEncryptedClaimsNull verifies null claims are neither encrypted nor decrypted
+func TestWithEncryptedClaimsNull(t *testing.T) {
+	secret := []byte("test-secret")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	type nullableClaims struct {
+		Claims
+		SSN *string `json:"ssn"`
+	}
+
+	token, err := Marshal(Header{Alg: HS256}, nullableClaims{Claims: Claims{Subject: "user123"}}, secret, WithEncryptedClaims(key, "ssn"))
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded nullableClaims
+
+	if err := Unmarshal(token, &decoded, secret, WithEncryptedClaims(key, "ssn")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.SSN != nil || decoded.Subject != "user123" {
+		t.Errorf("decoded = %+v, want a nil SSN", decoded)
+	}
+}
+
+// TestRewriteEncryptedClaims verifies Rewrite encrypts the named claims again
+func TestRewriteEncryptedClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	claims := piiClaims{Claims: Claims{Subject: "a"}, SSN: "078-05-1120"}
+
+	token, _ := Marshal(Header{Alg: HS256}, claims, secret, WithEncryptedClaims(key, "ssn"))
+
+	rewritten, err := Rewrite(token, secret, func(claims map[string]any) {
+		if claims["ssn"] != "078-05-1120" {
+			t.Errorf("mutate sees ssn = %v, want the plaintext", claims["ssn"])
+		}
+
+		claims["sub"] = "b"
+	}, WithEncryptedClaims(key, "ssn"))
+
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+
+	b64vals := b64values{}
+	_ = b64vals.unmarshal(rewritten)
+	payload, _ := decodeJWTBase64(b64vals.payload)
+
+	if strings.Contains(string(payload), "078-05-1120") {
+		t.Errorf("rewritten payload = %s, want ssn encrypted", payload)
+	}
+
+	var decoded piiClaims
+
+	if err := Unmarshal(rewritten, &decoded, secret, WithEncryptedClaims(key, "ssn")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.Subject != "b" || decoded.SSN != claims.SSN {
+		t.Errorf("decoded = %+v, want sub b and the original ssn", decoded)
+	}
+}
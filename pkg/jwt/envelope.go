@@ -0,0 +1,24 @@
+package jwt
+
+import "encoding/json"
+
+// UnmarshalFromField decodes and validates a JWT carried in the named string
+// field of a JSON object, such as {"token":"eyJ..."}, like Unmarshal. When
+// data is not a JSON object or the field is missing or not a string,
+// ErrInvalidTokenField is returned; errors from verifying the token itself are
+// returned unchanged.
+func UnmarshalFromField(data []byte, field string, claims any, secret []byte, opts ...Option) error {
+	var envelope map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return ErrInvalidTokenField
+	}
+
+	var jws string
+
+	if raw, ok := envelope[field]; !ok || json.Unmarshal(raw, &jws) != nil || string(raw) == "null" {
+		return ErrInvalidTokenField
+	}
+
+	return Unmarshal(jws, claims, secret, opts...)
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import "testing"
+
+// TestUnmarshalFromField verifies tokens are extracted from a JSON envelope and then verified
+func TestUnmarshalFromField(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+	forged, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, []byte("other-secret"))
+
+	tests := []struct {
+		name    string
+		data    string
+		field   string
+		wantErr error
+	}{
+		{
+			name:    "token field",
+			data:    `{"token":"` + token + `","expires_in":3600}`,
+			field:   "token",
+			wantErr: nil,
+		},
+		{
+			name:    "other field name",
+			data:    `{"access_token":"` + token + `"}`,
+			field:   "access_token",
+			wantErr: nil,
+		},
+		{
+			name:    "missing field",
+			data:    `{"access_token":"` + token + `"}`,
+			field:   "token",
+			wantErr: ErrInvalidTokenField,
+		},
+		{
+			name:    "null field",
+			data:    `{"token":null}`,
+			field:   "token",
+			wantErr: ErrInvalidTokenField,
+		},
+		{
+			name:    "non-string field",
+			data:    `{"token":{"jws":"` + token + `"}}`,
+			field:   "token",
+			wantErr: ErrInvalidTokenField,
+		},
+		{
+			name:    "not a JSON object",
+			data:    `"` + token + `"`,
+			field:   "token",
+			wantErr: ErrInvalidTokenField,
+		},
+		{
+			name:    "invalid token",
+			data:    `{"token":"not-a-token"}`,
+			field:   "token",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "forged token",
+			data:    `{"token":"` + forged + `"}`,
+			field:   "token",
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Claims
+
+			err := UnmarshalFromField([]byte(tt.data), tt.field, &decoded, secret)
+
+			if err != tt.wantErr {
+				t.Fatalf("UnmarshalFromField() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && decoded.Subject != "user123" {
+				t.Errorf("Subject = %q, want %q", decoded.Subject, "user123")
+			}
+		})
+	}
+}
@@ -6,6 +6,12 @@ var (
 	// ErrInvalidToken is returned when the token is invalid
 	ErrInvalidToken = errors.New("jwt: invalid token")
 
+	// ErrMalformedClaims is returned when a registered claim has an invalid JSON type
+	ErrMalformedClaims = errors.New("jwt: malformed claims")
+
+	// ErrMalformedHeader is returned when the header is not a JSON object
+	ErrMalformedHeader = errors.New("jwt: malformed header")
+
 	// ErrSignatureMismatch is returned when the signature does not match
 	ErrSignatureMismatch = errors.New("jwt: signature mismatch during verification")
 
@@ -15,8 +21,89 @@ var (
 	// ErrTokenNotValidYet is returned when the token is used before its 'nbf' (not before) time
 	ErrTokenNotValidYet = errors.New("jwt: token is not valid yet")
 
+	// ErrNotBeforeTooFarAhead is returned when the 'nbf' (not before) time lies beyond the accepted future bound
+	ErrNotBeforeTooFarAhead = errors.New("jwt: token not before time too far in the future")
+
 	// ErrTokenUsedBeforeIssued is returned when the token is used before its 'iat' (issued at) time
 	ErrTokenUsedBeforeIssued = errors.New("jwt: token used before issued")
+
+	// ErrIssuedAtOutOfWindow is returned when the 'iat' (issued at) time falls outside the accepted window
+	ErrIssuedAtOutOfWindow = errors.New("jwt: token issued at time outside accepted window")
+
+	// ErrTokenTTLExceeded is returned when the 'exp' (expiration) claim lies too far after the 'iat' (issued at) claim
+	ErrTokenTTLExceeded = errors.New("jwt: token lifetime exceeds maximum")
+
+	// ErrTokenNotFresh is returned when the token was issued too long ago, or without an 'iat' (issued at) claim, for an operation requiring a fresh token
+	ErrTokenNotFresh = errors.New("jwt: token is not fresh")
+
+	// ErrTokenRevoked is returned when a revocation store reports the token as revoked
+	ErrTokenRevoked = errors.New("jwt: token is revoked")
+
+	// ErrInvalidIssuer is returned when the 'iss' (issuer) claim does not match the expected issuer
+	ErrInvalidIssuer = errors.New("jwt: invalid issuer")
+
+	// ErrInvalidSubject is returned when the 'sub' (subject) claim does not have the expected format
+	ErrInvalidSubject = errors.New("jwt: invalid subject")
+
+	// ErrInvalidAudience is returned when the 'aud' (audience) claim does not contain an expected audience
+	ErrInvalidAudience = errors.New("jwt: invalid audience")
+
+	// ErrInvalidAuthorizedParty is returned when the 'azp' (authorized party) claim is missing while required or does not match the client ID
+	ErrInvalidAuthorizedParty = errors.New("jwt: invalid authorized party")
+
+	// ErrInvalidTokenUse is returned when the 'token_use' claim does not match the expected use
+	ErrInvalidTokenUse = errors.New("jwt: invalid token use")
+
+	// ErrMissingRole is returned when the 'roles' claim does not list a required role
+	ErrMissingRole = errors.New("jwt: missing required role")
+
+	// ErrInvalidNonce is returned when the 'nonce' claim does not match the expected nonce
+	ErrInvalidNonce = errors.New("jwt: invalid nonce")
+
+	// ErrInvalidJTI is returned when the 'jti' (JWT ID) claim does not have the expected format
+	ErrInvalidJTI = errors.New("jwt: invalid jwt id")
+
+	// ErrInvalidTokenField is returned when a JSON envelope has no string field holding the token
+	ErrInvalidTokenField = errors.New("jwt: token field missing or not a string")
+
+	// ErrMissingCookie is returned when a request has no cookie holding the token
+	ErrMissingCookie = errors.New("jwt: missing token cookie")
+
+	// ErrClaimDecryption is returned when an encrypted claim cannot be decrypted
+	ErrClaimDecryption = errors.New("jwt: claim decryption failed")
+
+	// ErrNilClaimsTarget is returned when the claims to decode into are nil or a nil pointer
+	ErrNilClaimsTarget = errors.New("jwt: nil claims target")
+
+	// ErrMissingKeyID is returned when a 'kid' (key ID) header is required but absent
+	ErrMissingKeyID = errors.New("jwt: missing key id")
+
+	// ErrUnknownKeyID is returned when a key set holds no key for the 'kid' (key ID) header
+	ErrUnknownKeyID = errors.New("jwt: unknown key id")
+
+	// ErrUnexpectedAlgorithm is returned when the 'alg' header is not in the allowed algorithms
+	ErrUnexpectedAlgorithm = errors.New("jwt: unexpected algorithm")
+
+	// ErrMissingClaim is returned when a required claim is absent
+	ErrMissingClaim = errors.New("jwt: missing required claim")
+
+	// ErrInvalidPEM is returned when key data holds no PEM block
+	ErrInvalidPEM = errors.New("jwt: no PEM block found")
+
+	// ErrEncoderClosed is returned when writing to or closing an Encoder that is already closed
+	ErrEncoderClosed = errors.New("jwt: encoder is closed")
+
+	// ErrInvalidKeyType is returned when the key type does not match the algorithm
+	ErrInvalidKeyType = errors.New("jwt: invalid key type for algorithm")
+
+	// ErrInvalidKeySize is returned when a key of the right type has the wrong length for the algorithm
+	ErrInvalidKeySize = errors.New("jwt: invalid key size for algorithm")
+
+	// ErrAlgorithmMismatch is returned when the verification key belongs to another algorithm family than the 'alg' header
+	ErrAlgorithmMismatch = errors.New("jwt: algorithm does not match the key family")
+
+	// ErrNilKeyfunc is returned when VerifyBatchConcurrent is given a nil Keyfunc
+	ErrNilKeyfunc = errors.New("jwt: nil keyfunc")
 )
 
 // unsupportedAlgorithmError indicates the algorithm is not supported
@@ -28,6 +115,18 @@ func (e unsupportedAlgorithmError) Error() string {
 	return "jwt: unsupported algorithm: " + e.alg
 }
 
+// IsUnsupportedAlgorithm reports whether err, or any error it wraps, reports
+// an 'alg' header this package does not implement, e.g. PS256 from an issuer
+// not yet migrated, so callers can log and skip such tokens instead of
+// treating them as forged. Unmarshal reports an unknown algorithm before any
+// signature work, unless WithAllowedAlgorithms or another algorithm policy
+// rejects it first with ErrUnexpectedAlgorithm.
+func IsUnsupportedAlgorithm(err error) bool {
+	var target unsupportedAlgorithmError
+
+	return errors.As(err, &target)
+}
+
 // unsupportedTypeError indicates the token type is not supported
 type unsupportedTypeError struct {
 	typ string
@@ -36,3 +135,12 @@ type unsupportedTypeError struct {
 func (e unsupportedTypeError) Error() string {
 	return "jwt: unsupported type: " + e.typ
 }
+
+// unsupportedPEMTypeError indicates the PEM block does not hold a supported key format
+type unsupportedPEMTypeError struct {
+	typ string
+}
+
+func (e unsupportedPEMTypeError) Error() string {
+	return "jwt: unsupported PEM block type: " + e.typ
+}
@@ -0,0 +1,45 @@
+package jwt
+
+// Instance bundles settings shared by every token of a service profile, such
+// as an access-token-only service issuing "at+jwt" tokens.
+type Instance struct {
+	// DefaultType is the 'typ' header Marshal sets when Header.Typ is empty and
+	// the only type Unmarshal accepts; JWT when empty
+	DefaultType string
+
+	// Options are applied to every call before the options given to it
+	Options []Option
+}
+
+// Marshal generates a JWT like the package-level Marshal, defaulting the
+// 'typ' header to DefaultType.
+func (i *Instance) Marshal(header Header, claims any, key any, opts ...Option) (string, error) {
+	if header.Typ == "" {
+		header.Typ = i.defaultType()
+	}
+
+	return Marshal(header, claims, key, i.options(opts)...)
+}
+
+// Unmarshal decodes and validates a JWT like the package-level Unmarshal,
+// accepting DefaultType as the 'typ' header unless the options give
+// WithAllowedTypes.
+func (i *Instance) Unmarshal(jws string, claims any, key any, opts ...Option) error {
+	return Unmarshal(jws, claims, key, i.options(opts)...)
+}
+
+func (i *Instance) defaultType() string {
+	if i.DefaultType == "" {
+		return JWT
+	}
+
+	return i.DefaultType
+}
+
+func (i *Instance) options(opts []Option) []Option {
+	all := make([]Option, 0, 1+len(i.Options)+len(opts))
+	all = append(all, WithAllowedTypes(i.defaultType()))
+	all = append(all, i.Options...)
+
+	return append(all, opts...)
+}
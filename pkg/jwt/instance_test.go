@@ -0,0 +1,57 @@
+package jwt
+
+import "testing"
+
+// TestInstanceDefaultType verifies Instance defaults and accepts its configured type
+func TestInstanceDefaultType(t *testing.T) {
+	secret := []byte("test-secret")
+	access := &Instance{DefaultType: "at+jwt"}
+
+	token, err := access.Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	header, _ := DecodeHeader(token)
+
+	if header.Typ != "at+jwt" {
+		t.Errorf("Typ = %q, want %q", header.Typ, "at+jwt")
+	}
+
+	if err := access.Unmarshal(token, &Claims{}, secret); err != nil {
+		t.Errorf("Instance.Unmarshal() error = %v", err)
+	}
+
+	if err := Unmarshal(token, &Claims{}, secret); err != (unsupportedTypeError{typ: "at+jwt"}) {
+		t.Errorf("Unmarshal() error = %v, want unsupported type", err)
+	}
+
+	t.Run("explicit typ wins", func(t *testing.T) {
+		token, _ := access.Marshal(Header{Alg: HS256, Typ: JWT}, Claims{}, secret)
+
+		if err := access.Unmarshal(token, &Claims{}, secret); err != (unsupportedTypeError{typ: JWT}) {
+			t.Errorf("Instance.Unmarshal() error = %v, want unsupported type", err)
+		}
+
+		if err := access.Unmarshal(token, &Claims{}, secret, WithAllowedTypes(JWT)); err != nil {
+			t.Errorf("Instance.Unmarshal() with WithAllowedTypes error = %v", err)
+		}
+	})
+
+	t.Run("package defaults are unchanged", func(t *testing.T) {
+		token, _ := (&Instance{}).Marshal(Header{Alg: HS256}, Claims{}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+
+	t.Run("instance options apply", func(t *testing.T) {
+		strict := &Instance{DefaultType: "at+jwt", Options: []Option{WithIssuer("issuer")}}
+
+		if err := strict.Unmarshal(token, &Claims{}, secret); err != ErrInvalidIssuer {
+			t.Errorf("Instance.Unmarshal() error = %v, want %v", err, ErrInvalidIssuer)
+		}
+	})
+}
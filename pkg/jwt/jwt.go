@@ -4,11 +4,11 @@ package jwt
 import (
 	"bytes"
 	"crypto/hmac"
-	"crypto/sha256"
-	"crypto/sha512"
+	"encoding/base64"
 	"encoding/json"
 	"hash"
-	"strings"
+	"io"
+	"reflect"
 	"time"
 )
 
@@ -17,6 +17,14 @@ const (
 	HS256 = "HS256"
 	HS384 = "HS384"
 	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	EdDSA = "EdDSA"
+	None  = "none"
 	JWT   = "JWT"
 )
 
@@ -28,39 +36,121 @@ type Claimer interface {
 // Header represents the JWT header
 type Header struct {
 	Alg string `json:"alg"`
-	Typ string `json:"typ"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// unverified marks headers returned by ParseUnverified
+	unverified bool
+}
+
+// Unverified reports whether the header comes from ParseUnverified, so the
+// signature of its token was never checked and nothing in it can be trusted.
+func (h Header) Unverified() bool {
+	return h.unverified
 }
 
 // Claims implements the Claimer interface and includes standard JWT claims.
 type Claims struct {
-	Issuer    string `json:"iss,omitempty"`
-	Subject   string `json:"sub,omitempty"`
-	Audience  string `json:"aud,omitempty"`
-	ExpiresAt int64  `json:"exp,omitempty"`
-	NotBefore int64  `json:"nbf,omitempty"`
-	IssuedAt  int64  `json:"iat,omitempty"`
-	ID        string `json:"jti,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
 }
 
 // Valid validates the claims against the standard JWT rules.
 func (c *Claims) Valid() error {
-	now := time.Now().Unix()
+	return c.validate(newOptions(nil))
+}
 
-	if c.ExpiresAt > 0 && now >= c.ExpiresAt {
-		return ErrTokenExpired
+// ExpiresWithin reports whether the claims expire within d of now, which is
+// the real clock unless WithNow is given. Claims without 'exp' never expire.
+func (c *Claims) ExpiresWithin(d time.Duration, opts ...Option) bool {
+	if c.ExpiresAt == 0 {
+		return false
 	}
 
-	if c.NotBefore > 0 && now < c.NotBefore {
-		return ErrTokenNotValidYet
+	o := newOptions(opts)
+
+	return o.fromNumericDate(c.ExpiresAt).Sub(o.now()) < d
+}
+
+// ToMap returns the claims keyed by their JSON names, omitting unset claims as
+// the JSON encoding does. Times are int64 NumericDates and a single audience is
+// a string, several a []string, matching the encoded form.
+func (c Claims) ToMap() map[string]any {
+	m := map[string]any{}
+
+	if c.Issuer != "" {
+		m["iss"] = c.Issuer
+	}
+
+	if c.Subject != "" {
+		m["sub"] = c.Subject
 	}
 
-	if c.IssuedAt > 0 && now < c.IssuedAt {
-		return ErrTokenUsedBeforeIssued
+	if len(c.Audience) == 1 {
+		m["aud"] = c.Audience[0]
+	} else if len(c.Audience) > 1 {
+		m["aud"] = []string(c.Audience)
+	}
+
+	if c.ExpiresAt != 0 {
+		m["exp"] = c.ExpiresAt
+	}
+
+	if c.NotBefore != 0 {
+		m["nbf"] = c.NotBefore
+	}
+
+	if c.IssuedAt != 0 {
+		m["iat"] = c.IssuedAt
+	}
+
+	if c.ID != "" {
+		m["jti"] = c.ID
+	}
+
+	return m
+}
+
+func (c *Claims) validate(o *options) error {
+	if errs := c.violations(o); len(errs) > 0 {
+		return errs[0]
 	}
 
 	return nil
 }
 
+// violations returns every time rule the claims break, in validation order.
+func (c *Claims) violations(o *options) []error {
+	var errs []error
+
+	now := o.numericDate(o.now())
+	leeway := int64(o.leeway / o.timeUnit)
+
+	// A negative 'exp' lies before the epoch, so it is expired rather than unset
+	if c.ExpiresAt != 0 && now-leeway >= c.ExpiresAt && !o.allowExpired {
+		errs = append(errs, ErrTokenExpired)
+	}
+
+	// An 'nbf' beyond the configured bound is malformed rather than early
+	if c.NotBefore > 0 && o.limitNotBefore && c.NotBefore > o.numericDate(o.now().Add(o.maxNotBefore))+leeway {
+		errs = append(errs, ErrNotBeforeTooFarAhead)
+	} else if c.NotBefore > 0 && now+leeway < c.NotBefore {
+		errs = append(errs, ErrTokenNotValidYet)
+	}
+
+	// A configured issued-at window replaces the default "iat <= now" rule
+	if c.IssuedAt > 0 && now+leeway < c.IssuedAt && !o.issuedAtWindow {
+		errs = append(errs, ErrTokenUsedBeforeIssued)
+	}
+
+	return errs
+}
+
 func (h *Header) marshal() (string, error) {
 	var jsonBuf bytes.Buffer
 
@@ -77,45 +167,146 @@ func (h *Header) marshal() (string, error) {
 }
 
 func (h *Header) unmarshal(encodedHeader string) error {
+	return h.decode(encodedHeader, false)
+}
+
+// decode decodes the header, optionally rejecting parameters Header does not model.
+func (h *Header) decode(encodedHeader string, disallowUnknownFields bool) error {
 	jsonHeader, err := decodeJWTBase64(encodedHeader)
 
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(jsonHeader, h)
+	// Other JSON values would otherwise surface as a stdlib type error, or be
+	// silently accepted in the case of null
+	if trimmed := bytes.TrimLeft(jsonHeader, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '{' {
+		return ErrMalformedHeader
+	}
+
+	if !disallowUnknownFields {
+		return json.Unmarshal(jsonHeader, h)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonHeader))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(h); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// signer returns the keyed hash of an HMAC header algorithm, for the streaming
+// paths that feed the signing input incrementally. Other algorithms are
+// reported as unsupported; the key must be a []byte secret.
+func (h *Header) signer(key any) (hash.Hash, error) {
+	method, err := methodForAlg(h.Alg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hm, ok := method.(hmacMethod)
+
+	if !ok {
+		return nil, unsupportedAlgorithmError{alg: h.Alg}
+	}
+
+	secret, ok := key.([]byte)
+
+	if !ok {
+		return nil, ErrInvalidKeyType
+	}
+
+	return hmac.New(hm.hash.New, secret), nil
+}
+
+// sign computes the raw signature of the signing input for the header algorithm.
+func (h *Header) sign(signingInput string, key any) ([]byte, error) {
+	return h.sum([]byte(signingInput), key)
 }
 
-func (h *Header) signer(secret []byte) (hash.Hash, error) {
-	switch strings.ToUpper(h.Alg) {
-	case HS256:
-		return hmac.New(sha256.New, secret), nil
-	case HS384:
-		return hmac.New(sha512.New384, secret), nil
-	case HS512:
-		return hmac.New(sha512.New, secret), nil
+// sum is sign for a signing input already held as bytes.
+func (h *Header) sum(signingInput []byte, key any) ([]byte, error) {
+	method, err := methodFor(h.Alg, key)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, unsupportedAlgorithmError{alg: h.Alg}
+	return method.sign(signingInput, key)
 }
 
 type payload struct {
-	claims any
+	claims     any
+	raw        []byte
+	registered *Claims
+	fields     map[string]json.RawMessage
+	useNumber  bool
+
+	// stringyDates accepts exp, nbf, and iat encoded as JSON strings
+	stringyDates bool
+
+	// cipher decrypts the claims encrypted with WithEncryptedClaims
+	cipher *claimCipher
 }
 
 func (p *payload) marshal() (string, error) {
-	var buf bytes.Buffer
+	encoded, err := p.appendEncoded(nil)
+
+	if err != nil {
+		return "", err
+	}
 
-	enc := json.NewEncoder(&buf)
+	return string(encoded), nil
+}
+
+// appendEncoded appends the base64url-encoded JSON of the claims to dst.
+func (p *payload) appendEncoded(dst []byte) ([]byte, error) {
+	err := p.encodeJSON(func(jsonClaims []byte) {
+		dst = appendJWTBase64(dst, jsonClaims)
+	})
+
+	return dst, err
+}
+
+// encodeJSON passes the JSON encoding of the claims to fn. The bytes may live
+// in a pooled buffer and are only valid until fn returns.
+func (p *payload) encodeJSON(fn func(jsonClaims []byte)) error {
+	// Plain Claims, by far the most common payload, skip the reflective encoder
+	if c, ok := p.claims.(Claims); ok {
+		if jsonClaims, ok := appendClaimsJSON(nil, &c); ok {
+			fn(jsonClaims)
+			return nil
+		}
+	}
+
+	if c, ok := p.claims.(*Claims); ok && c != nil {
+		if jsonClaims, ok := appendClaimsJSON(nil, c); ok {
+			fn(jsonClaims)
+			return nil
+		}
+	}
+
+	buf := getJSONBuffer()
+	defer putJSONBuffer(buf)
+
+	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
 
 	if err := enc.Encode(p.claims); err != nil {
-		return "", err
+		return err
 	}
 
-	jsonClaims := bytes.TrimSpace(buf.Bytes())
+	fn(bytes.TrimSpace(buf.Bytes()))
 
-	return encodeJWTBase64(jsonClaims), nil
+	return nil
 }
 
 func (p *payload) unmarshal(encodedPayload string) error {
@@ -125,83 +316,325 @@ func (p *payload) unmarshal(encodedPayload string) error {
 		return err
 	}
 
-	return json.Unmarshal(jsonClaims, p.claims)
+	if p.stringyDates {
+		if jsonClaims, err = unquoteNumericDates(jsonClaims); err != nil {
+			return err
+		}
+	}
+
+	if p.cipher != nil {
+		if jsonClaims, err = p.cipher.decrypt(jsonClaims); err != nil {
+			return err
+		}
+	}
+
+	p.raw = jsonClaims
+
+	if isNilPointer(p.claims) {
+		return ErrNilClaimsTarget
+	}
+
+	if err := p.decode(jsonClaims); err != nil {
+		return err
+	}
+
+	// Targets holding Claims already carry the registered claims, so they are
+	// not parsed a second time for validation
+	if r, ok := p.claims.(registeredClaimer); ok {
+		p.registered = r.registeredClaims()
+	}
+
+	return nil
+}
+
+func (p *payload) decode(jsonClaims []byte) error {
+	if !p.useNumber {
+		return json.Unmarshal(jsonClaims, p.claims)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonClaims))
+	dec.UseNumber()
+
+	return dec.Decode(p.claims)
+}
+
+// registeredClaimer is implemented by *Claims and by pointers to types
+// embedding Claims.
+type registeredClaimer interface {
+	registeredClaims() *Claims
+}
+
+func (c *Claims) registeredClaims() *Claims {
+	return c
+}
+
+// isNilPointer reports whether v is nil or a typed nil pointer.
+func isNilPointer(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// registeredClaims returns the registered claims of the payload, whatever the
+// type of the caller's claims. They are decoded separately only when the
+// claims do not hold them.
+func (p *payload) registeredClaims() (*Claims, error) {
+	if p.registered != nil {
+		return p.registered, nil
+	}
+
+	c := &Claims{}
+
+	if err := json.Unmarshal(p.raw, c); err != nil {
+		return nil, err
+	}
+
+	p.registered = c
+
+	return c, nil
+}
+
+// hasZeroExpiry reports whether the payload explicitly holds an 'exp' claim of
+// 0. Claims cannot tell it apart from an absent 'exp', but RFC 7519 reads it
+// as the epoch, so the token expired in 1970. Presence is decided from the
+// decoded claims, so escaped member names are matched as encoding/json does.
+func (p *payload) hasZeroExpiry() bool {
+	// A non-zero 'exp' was decoded into the registered claims and needs no parse
+	if p.registered != nil && p.registered.ExpiresAt != 0 {
+		return false
+	}
+
+	fields, err := p.rawClaims()
+
+	if err != nil {
+		return false
+	}
+
+	var exp *float64
+
+	return json.Unmarshal(fields["exp"], &exp) == nil && exp != nil && *exp == 0
+}
+
+// rawClaims decodes the payload into its top-level claims, keeping each value
+// as raw JSON so that claims outside Claims can be inspected.
+func (p *payload) rawClaims() (map[string]json.RawMessage, error) {
+	if p.fields != nil {
+		return p.fields, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(p.raw, &fields); err != nil {
+		return nil, err
+	}
+
+	p.fields = fields
+
+	return fields, nil
 }
 
 type token struct {
 	header  Header
 	payload payload
+	raw     b64values
+
+	// signingContext is bound into the signature without being transmitted
+	signingContext []byte
+
+	// verifyDuration is the time spent computing and comparing the signature
+	verifyDuration time.Duration
+
+	// keyIndex is the index of the candidate key that verified the signature
+	keyIndex int
+
+	// verifiedAlg is the algorithm the signature is verified with, which
+	// WithForcedAlgorithm may set apart from the 'alg' header
+	verifiedAlg string
+}
+
+// algorithm returns the algorithm the signature was verified with, falling
+// back to the 'alg' header for tokens rejected before that was chosen.
+func (t *token) algorithm() string {
+	if t.verifiedAlg != "" {
+		return t.verifiedAlg
+	}
+
+	return t.header.Alg
 }
 
-func (t *token) marshal(secret []byte) (string, error) {
-	signer, err := t.header.signer(secret)
+// signingInput returns the bytes the signature covers: the encoded header and
+// payload, followed by the signing context if one is set. The '.' separator
+// cannot occur in base64url, so the context boundary is unambiguous.
+func (t *token) signingInput(header, payload string) string {
+	if len(t.signingContext) == 0 {
+		return header + "." + payload
+	}
+
+	return header + "." + payload + "." + string(t.signingContext)
+}
+
+func (t *token) marshal(key any) (string, error) {
+	key, err := resolveKey(key, t.header)
 
 	if err != nil {
 		return "", err
 	}
 
-	tokenHeader, err := t.header.marshal()
+	method, err := methodFor(t.header.Alg, key)
 
 	if err != nil {
 		return "", err
 	}
 
-	tokenPayload, err := t.payload.marshal()
+	key = primaryKey(key)
+
+	tokenHeader, err := t.header.marshal()
 
 	if err != nil {
 		return "", err
 	}
 
-	signingMessage := tokenHeader + "." + tokenPayload
+	// The token is assembled in a single buffer that also serves as the
+	// signing input, so large payloads are not copied between segments. It is
+	// sized from the encoded claims, with room for signatures of up to 64
+	// bytes such as HS512, ES256, and EdDSA ones.
+	var buf []byte
+
+	err = t.payload.encodeJSON(func(jsonClaims []byte) {
+		size := len(tokenHeader) + 1 + base64.RawURLEncoding.EncodedLen(len(jsonClaims)) + 1 + base64.RawURLEncoding.EncodedLen(64)
+
+		buf = make([]byte, 0, size)
+		buf = appendJWTBase64(append(append(buf, tokenHeader...), '.'), jsonClaims)
+	})
 
-	if _, err := signer.Write([]byte(signingMessage)); err != nil {
+	if err != nil {
 		return "", err
 	}
 
-	tokenSignature := encodeJWTBase64(signer.Sum(nil))
+	signingMessage := buf
+
+	if len(t.signingContext) > 0 {
+		signingMessage = append(append(append([]byte(nil), buf...), '.'), t.signingContext...)
+	}
+
+	signature, err := method.sign(signingMessage, key)
 
-	b64vals := b64values{
-		header:    tokenHeader,
-		payload:   tokenPayload,
-		signature: tokenSignature,
+	if err != nil {
+		return "", err
 	}
 
-	return b64vals.marshal(), nil
+	buf = appendJWTBase64(append(buf, '.'), signature)
+
+	return string(buf), nil
 }
 
-func (t *token) unmarshal(jws string, secret []byte) error {
+// unmarshal verifies the signature of jws and decodes its header and payload.
+// Cheap structural checks run first so that garbage tokens are rejected before
+// any HMAC is computed.
+func (t *token) unmarshal(jws string, key any, o *options) error {
 	b64vals := b64values{}
 
 	if err := b64vals.unmarshal(jws); err != nil {
 		return err
 	}
 
-	expectedSignature, err := decodeJWTBase64(b64vals.signature)
-	if err != nil {
+	// The signature covers the canonical segments, so the signing input below
+	// is rebuilt from the normalized forms rather than the received bytes
+	if o.lenientBase64 {
+		b64vals = b64vals.normalize()
+	}
+
+	// Only the signature is read leniently; the signing input is untouched
+	if o.lenientSigB64 {
+		b64vals.signature = canonicalBase64(b64vals.signature)
+	}
+
+	if b64vals.header == "" || b64vals.payload == "" {
+		return ErrInvalidToken
+	}
+
+	if !isBase64URL(b64vals.header) || !isBase64URL(b64vals.payload) || !isBase64URL(b64vals.signature) {
 		return ErrInvalidToken
 	}
 
-	if err := t.header.unmarshal(b64vals.header); err != nil {
+	t.raw = b64vals
+	t.signingContext = o.signingContext
+
+	if err := t.header.decode(b64vals.header, o.strictHeaders); err != nil {
+		return err
+	}
+
+	if o.requireKid && t.header.Kid == "" {
+		return ErrMissingKeyID
+	}
+
+	for _, check := range o.headerChecks {
+		if err := check(t.header); err != nil {
+			return err
+		}
+	}
+
+	verifier, err := o.verificationHeader(t.header)
+
+	if err != nil {
 		return err
 	}
 
-	signer, err := t.header.signer(secret)
+	t.verifiedAlg = verifier.Alg
+
+	if !o.allowsAlgorithm(verifier.Alg) {
+		return ErrUnexpectedAlgorithm
+	}
+
+	// Unknown algorithms, and "none" without the opt-in, are reported before
+	// the signature is even decoded or the key resolved
+	method, err := o.verificationMethod(verifier.Alg, key)
+
+	if err != nil {
+		return err
+	}
 
+	expectedSignature, err := decodeJWTBase64(b64vals.signature)
 	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := method.checkLength(len(expectedSignature)); err != nil {
 		return err
 	}
 
-	signingMessage := b64vals.header + "." + b64vals.payload
+	key, err = resolveKey(key, verifier)
 
-	if _, err := signer.Write([]byte(signingMessage)); err != nil {
+	if err != nil {
 		return err
 	}
 
-	computedSignature := signer.Sum(nil)
+	signingMessage := []byte(t.signingInput(b64vals.header, b64vals.payload))
+
+	start := time.Now()
 
-	if !hmac.Equal(computedSignature, expectedSignature) {
-		return ErrSignatureMismatch
+	t.keyIndex, err = verifyCandidates(method, signingMessage, expectedSignature, key)
+	t.verifyDuration = time.Since(start)
+
+	if err == ErrSignatureMismatch && o.verifyDebug != nil {
+		// Only HMAC signatures can be recomputed with the verification key
+		if hm, ok := method.(hmacMethod); ok {
+			computedSignature, _ := hm.sign(signingMessage, primaryKey(key))
+			o.verifyDebug(computedSignature, append([]byte(nil), expectedSignature...))
+		}
+	}
+
+	if err != nil {
+		return err
 	}
 
+	t.payload.useNumber = o.useNumber
+	t.payload.stringyDates = o.stringyDates
+	t.payload.cipher = o.claimCipher
+
 	return t.payload.unmarshal(b64vals.payload)
 }
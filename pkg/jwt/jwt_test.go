@@ -2,6 +2,8 @@ package jwt
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -304,6 +306,36 @@ func TestHeaderMarshaling(t *testing.T) {
 	}
 }
 
+// TestHeaderNonObject verifies headers holding other JSON values are malformed
+func TestHeaderNonObject(t *testing.T) {
+	secret := []byte("test-secret")
+	payload := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+
+	for _, header := range []string{`"HS256"`, `[]`, `null`, `42`} {
+		t.Run(header, func(t *testing.T) {
+			encoded := encodeJWTBase64([]byte(header))
+
+			var decoded Header
+
+			if err := decoded.unmarshal(encoded); err != ErrMalformedHeader {
+				t.Errorf("Header.unmarshal() error = %v, want %v", err, ErrMalformedHeader)
+			}
+
+			signature, _ := ComputeSignature(encoded+"."+payload, HS256, secret)
+
+			if err := Unmarshal(encoded+"."+payload+"."+signature, &Claims{}, secret); err != ErrMalformedHeader {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrMalformedHeader)
+			}
+		})
+	}
+
+	var decoded Header
+
+	if err := decoded.unmarshal(encodeJWTBase64([]byte(` {"alg":"HS256"}`))); err != nil {
+		t.Errorf("Header.unmarshal() error = %v for an object with leading whitespace", err)
+	}
+}
+
 // TestHeaderSigner verifies algorithm to hash function mapping
 func TestHeaderSigner(t *testing.T) {
 	secret := []byte("secret")
@@ -454,6 +486,171 @@ func TestPayloadMarshaling(t *testing.T) {
 	}
 }
 
+// marshalerClaims encodes itself through a custom json.Marshaler
+type marshalerClaims struct {
+	Claims
+	Scopes []string
+}
+
+func (c marshalerClaims) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"sub":   c.Subject,
+		"iat":   c.IssuedAt,
+		"scope": strings.Join(c.Scopes, " "),
+	})
+}
+
+func (c *marshalerClaims) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Subject  string `json:"sub"`
+		IssuedAt int64  `json:"iat"`
+		Scope    string `json:"scope"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c.Subject = raw.Subject
+	c.IssuedAt = raw.IssuedAt
+	c.Scopes = strings.Fields(raw.Scope)
+
+	return nil
+}
+
+// pointerMarshalerClaims implements json.Marshaler on the pointer receiver
+type pointerMarshalerClaims struct {
+	Value string
+}
+
+func (c *pointerMarshalerClaims) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"custom": c.Value})
+}
+
+// TestPayloadRegisteredClaimsSinglePass verifies targets holding Claims supply
+// the registered claims without a second decode
+func TestPayloadRegisteredClaimsSinglePass(t *testing.T) {
+	encoded := encodeJWTBase64([]byte(`{"iss":"issuer","sub":"user123","role":"admin"}`))
+
+	type customClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	t.Run("embedded claims", func(t *testing.T) {
+		target := &customClaims{}
+		p := payload{claims: target}
+
+		if err := p.unmarshal(encoded); err != nil {
+			t.Fatalf("payload.unmarshal() error = %v", err)
+		}
+
+		registered, err := p.registeredClaims()
+
+		if err != nil {
+			t.Fatalf("registeredClaims() error = %v", err)
+		}
+
+		if registered != &target.Claims {
+			t.Error("registeredClaims() decoded the payload again instead of using the target")
+		}
+
+		if registered.Issuer != "issuer" || registered.Subject != "user123" {
+			t.Errorf("registeredClaims() = %+v", registered)
+		}
+	})
+
+	t.Run("map claims", func(t *testing.T) {
+		p := payload{claims: &map[string]any{}}
+
+		if err := p.unmarshal(encoded); err != nil {
+			t.Fatalf("payload.unmarshal() error = %v", err)
+		}
+
+		registered, err := p.registeredClaims()
+
+		if err != nil || registered.Issuer != "issuer" {
+			t.Errorf("registeredClaims() = %+v, %v", registered, err)
+		}
+	})
+}
+
+// TestPayloadCustomMarshaler verifies json.Marshaler and json.Unmarshaler are honored
+func TestPayloadCustomMarshaler(t *testing.T) {
+	secret := []byte("secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	t.Run("payload uses MarshalJSON", func(t *testing.T) {
+		p := payload{claims: marshalerClaims{Claims: Claims{Subject: "user123"}, Scopes: []string{"read", "write"}}}
+
+		encoded, err := p.marshal()
+		if err != nil {
+			t.Fatalf("payload.marshal() error = %v", err)
+		}
+
+		decoded, _ := base64.RawURLEncoding.DecodeString(encoded)
+		want := `{"iat":0,"scope":"read write","sub":"user123"}`
+
+		if string(decoded) != want {
+			t.Errorf("payload = %s, want %s", decoded, want)
+		}
+	})
+
+	t.Run("pointer receiver MarshalJSON", func(t *testing.T) {
+		p := payload{claims: &pointerMarshalerClaims{Value: "x"}}
+
+		encoded, err := p.marshal()
+		if err != nil {
+			t.Fatalf("payload.marshal() error = %v", err)
+		}
+
+		decoded, _ := base64.RawURLEncoding.DecodeString(encoded)
+
+		if string(decoded) != `{"custom":"x"}` {
+			t.Errorf("payload = %s, want %s", decoded, `{"custom":"x"}`)
+		}
+	})
+
+	t.Run("round trip uses UnmarshalJSON", func(t *testing.T) {
+		claims := marshalerClaims{Claims: Claims{Subject: "user123"}, Scopes: []string{"read", "write"}}
+
+		token, err := Marshal(header, claims, secret)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		var decoded marshalerClaims
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.Subject != "user123" || strings.Join(decoded.Scopes, ",") != "read,write" {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	})
+
+	t.Run("marshal options keep MarshalJSON", func(t *testing.T) {
+		now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		claims := &marshalerClaims{Claims: Claims{Subject: "user123"}, Scopes: []string{"read"}}
+
+		token, err := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		var decoded map[string]any
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded["scope"] != "read" || decoded["iat"] != float64(now.Unix()) {
+			t.Errorf("decoded = %v", decoded)
+		}
+	})
+}
+
 // TestClaimsValidation verifies RFC 7519 claims validation
 func TestClaimsValidation(t *testing.T) {
 	now := time.Now().Unix()
@@ -531,7 +728,7 @@ func TestClaimsValidation(t *testing.T) {
 			claims: Claims{
 				Issuer:    "test-issuer",
 				Subject:   "user-123",
-				Audience:  "test-audience",
+				Audience:  Audience{"test-audience"},
 				ExpiresAt: now + 3600,
 				NotBefore: now - 60,
 				IssuedAt:  now - 60,
@@ -557,6 +754,147 @@ func TestClaimsValidation(t *testing.T) {
 	}
 }
 
+// TestClaimsValidationLeeway verifies the leeway applies uniformly to exp, nbf, and iat
+func TestClaimsValidationLeeway(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	leeway := WithLeeway(time.Minute)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		opts    []Option
+		wantErr error
+	}{
+		{name: "iat in future within leeway", claims: Claims{IssuedAt: now.Add(30 * time.Second).Unix()}, opts: []Option{leeway}},
+		{name: "iat at the leeway bound", claims: Claims{IssuedAt: now.Add(time.Minute).Unix()}, opts: []Option{leeway}},
+		{name: "iat beyond leeway", claims: Claims{IssuedAt: now.Add(61 * time.Second).Unix()}, opts: []Option{leeway}, wantErr: ErrTokenUsedBeforeIssued},
+		{name: "iat in future without leeway", claims: Claims{IssuedAt: now.Add(30 * time.Second).Unix()}, wantErr: ErrTokenUsedBeforeIssued},
+		{name: "nbf within leeway", claims: Claims{NotBefore: now.Add(30 * time.Second).Unix()}, opts: []Option{leeway}},
+		{name: "nbf beyond leeway", claims: Claims{NotBefore: now.Add(2 * time.Minute).Unix()}, opts: []Option{leeway}, wantErr: ErrTokenNotValidYet},
+		{name: "exp passed within leeway", claims: Claims{ExpiresAt: now.Add(-30 * time.Second).Unix()}, opts: []Option{leeway}},
+		{name: "exp at the leeway bound", claims: Claims{ExpiresAt: now.Add(-time.Minute).Unix()}, opts: []Option{leeway}, wantErr: ErrTokenExpired},
+		{name: "negative leeway ignored", claims: Claims{ExpiresAt: now.Unix()}, opts: []Option{WithLeeway(-time.Hour)}, wantErr: ErrTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256}, tt.claims, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, append(tt.opts, WithNow(now))...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("expired report honors leeway", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: now.Add(-30 * time.Second).Unix()}, secret)
+
+		expired, err := UnmarshalAllowExpired(token, &Claims{}, secret, WithNow(now), leeway)
+
+		if err != nil || expired {
+			t.Errorf("UnmarshalAllowExpired() = %v, %v, want false, nil", expired, err)
+		}
+	})
+}
+
+// TestClaimsExpiresWithin verifies proactive refresh decisions
+func TestClaimsExpiresWithin(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		claims Claims
+		within time.Duration
+		want   bool
+	}{
+		{
+			name:   "expires inside the window",
+			claims: Claims{ExpiresAt: now.Add(2 * time.Minute).Unix()},
+			within: 5 * time.Minute,
+			want:   true,
+		},
+		{
+			name:   "expires after the window",
+			claims: Claims{ExpiresAt: now.Add(1 * time.Hour).Unix()},
+			within: 5 * time.Minute,
+			want:   false,
+		},
+		{
+			name:   "already expired",
+			claims: Claims{ExpiresAt: now.Add(-1 * time.Minute).Unix()},
+			within: 5 * time.Minute,
+			want:   true,
+		},
+		{
+			name:   "no expiration",
+			claims: Claims{},
+			within: 24 * time.Hour,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.ExpiresWithin(tt.within, WithNow(now)); got != tt.want {
+				t.Errorf("ExpiresWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("defaults to the real clock", func(t *testing.T) {
+		c := Claims{ExpiresAt: time.Now().Add(1 * time.Minute).Unix()}
+
+		if !c.ExpiresWithin(5 * time.Minute) {
+			t.Error("ExpiresWithin() = false, want true")
+		}
+	})
+}
+
+// TestClaimsToMap verifies the map matches the JSON encoding of the claims
+func TestClaimsToMap(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims Claims
+	}{
+		{name: "empty", claims: Claims{}},
+		{name: "single audience", claims: Claims{Issuer: "issuer", Subject: "user123", Audience: Audience{"api"}, ExpiresAt: 1700000000, ID: "id"}},
+		{name: "several audiences", claims: Claims{Audience: Audience{"api", "web"}, NotBefore: 1600000000, IssuedAt: 1600000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.claims.ToMap())
+
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			want, _ := json.Marshal(tt.claims)
+
+			// Maps encode with sorted keys, so compare the decoded forms
+			var gotFields, wantFields map[string]any
+
+			json.Unmarshal(got, &gotFields)
+			json.Unmarshal(want, &wantFields)
+
+			if !reflect.DeepEqual(gotFields, wantFields) {
+				t.Errorf("ToMap() encodes to %s, want %s", got, want)
+			}
+		})
+	}
+
+	m := Claims{ExpiresAt: 1700000000, Audience: Audience{"api"}}.ToMap()
+
+	if m["exp"] != int64(1700000000) || m["aud"] != "api" {
+		t.Errorf("ToMap() = %#v", m)
+	}
+
+	if _, ok := m["iss"]; ok {
+		t.Error("ToMap() holds an unset claim")
+	}
+}
+
 // TestTokenMarshalUnmarshal verifies end-to-end token creation and validation
 func TestTokenMarshalUnmarshal(t *testing.T) {
 	secret := []byte("test-secret-key-123")
@@ -721,6 +1059,83 @@ func TestSignatureVerification(t *testing.T) {
 	})
 }
 
+// countingKey is a key resolver that counts how often a key was requested
+type countingKey struct {
+	secret []byte
+	calls  int
+}
+
+func (k *countingKey) resolveKey(Header) (any, error) {
+	k.calls++
+	return k.secret, nil
+}
+
+// TestStructuralRejectsBeforeHMAC verifies garbage tokens fail before any key is used
+func TestStructuralRejectsBeforeHMAC(t *testing.T) {
+	secret := []byte("secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:    "empty header",
+			token:   "." + parts[1] + "." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "empty payload",
+			token:   parts[0] + ".." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "unsupported algorithm",
+			token:   encodeJWTBase64([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".",
+			wantErr: unsupportedAlgorithmError{alg: "none"},
+		},
+		{
+			name:    "truncated signature",
+			token:   parts[0] + "." + parts[1] + "." + parts[2][:20],
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "signature of another algorithm",
+			token:   parts[0] + "." + parts[1] + "." + encodeJWTBase64(make([]byte, 64)),
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &countingKey{secret: secret}
+
+			if err := Unmarshal(tt.token, &Claims{}, key); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if key.calls != 0 {
+				t.Errorf("key resolved %d times, want 0", key.calls)
+			}
+		})
+	}
+
+	t.Run("valid token is verified", func(t *testing.T) {
+		key := &countingKey{secret: secret}
+
+		if err := Unmarshal(token, &Claims{}, key); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+
+		if key.calls != 1 {
+			t.Errorf("key resolved %d times, want 1", key.calls)
+		}
+	})
+}
+
 // TestConstantTimeComparison verifies timing attack resistance
 func TestConstantTimeComparison(t *testing.T) {
 	secret := []byte("secret")
@@ -777,6 +1192,16 @@ func TestTypeHeaderValidation(t *testing.T) {
 			typ:     "",
 			wantErr: false,
 		},
+		{
+			name:    "lowercase jwt type",
+			typ:     "jwt",
+			wantErr: false,
+		},
+		{
+			name:    "mixed case Jwt type",
+			typ:     "Jwt",
+			wantErr: false,
+		},
 		{
 			name:    "invalid type",
 			typ:     "INVALID",
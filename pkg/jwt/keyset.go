@@ -0,0 +1,100 @@
+package jwt
+
+// keyResolver is implemented by keys that stand for several keys and pick one
+// from the token header.
+type keyResolver interface {
+	resolveKey(h Header) (any, error)
+}
+
+// resolveKey returns the key to use for a token with the given header.
+func resolveKey(key any, h Header) (any, error) {
+	if r, ok := key.(keyResolver); ok {
+		return r.resolveKey(h)
+	}
+
+	return key, nil
+}
+
+// candidateKeys are keys tried in order until one verifies the signature; the
+// first one signs.
+type candidateKeys []any
+
+// primaryKey returns the first of candidate keys, and any other key unchanged.
+func primaryKey(key any) any {
+	if candidates, ok := key.(candidateKeys); ok && len(candidates) > 0 {
+		return candidates[0]
+	}
+
+	return key
+}
+
+// verifyCandidates verifies signature with the resolved key, or with each of
+// candidate keys in order, and returns the index of the key that verified it.
+// Every candidate must belong to the family of method.
+func verifyCandidates(method signingMethod, message, signature []byte, key any) (int, error) {
+	candidates, ok := key.(candidateKeys)
+
+	if !ok {
+		candidates = candidateKeys{key}
+	}
+
+	for _, k := range candidates {
+		if err := checkKeyFamily(method, k); err != nil {
+			return 0, err
+		}
+	}
+
+	err := ErrSignatureMismatch
+
+	for i, k := range candidates {
+		if err = method.verify(message, signature, k); err != ErrSignatureMismatch {
+			return i, err
+		}
+	}
+
+	return 0, err
+}
+
+// SymmetricKeySet maps key IDs to HMAC secrets. It can be passed as the key to
+// Unmarshal, which then verifies the token with the secret named by its 'kid'
+// header, and to Marshal, which signs with the secret named by header.Kid.
+type SymmetricKeySet map[string][]byte
+
+// Marshal signs the token with the secret named activeKid and sets the 'kid'
+// header to activeKid so verifiers can resolve the key. ErrUnknownKeyID is
+// returned when the set has no such secret.
+func (s SymmetricKeySet) Marshal(header Header, claims any, activeKid string, opts ...Option) (string, error) {
+	header.Kid = activeKid
+
+	return Marshal(header, claims, s, opts...)
+}
+
+// Unmarshal verifies the token with the secret named by its 'kid' header and
+// decodes its claims like Unmarshal.
+func (s SymmetricKeySet) Unmarshal(jws string, claims any, opts ...Option) error {
+	return Unmarshal(jws, claims, s, opts...)
+}
+
+func (s SymmetricKeySet) resolveKey(h Header) (any, error) {
+	secret, ok := s[h.Kid]
+
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	return secret, nil
+}
+
+// algorithmKeys maps algorithms to the keys verifying them, for UnmarshalMulti.
+type algorithmKeys map[string]any
+
+func (k algorithmKeys) resolveKey(h Header) (any, error) {
+	key, ok := k[h.Alg]
+
+	if !ok {
+		return nil, ErrUnexpectedAlgorithm
+	}
+
+	// A key set registered for an algorithm still resolves by 'kid'
+	return resolveKey(key, h)
+}
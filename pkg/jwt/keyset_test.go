@@ -0,0 +1,70 @@
+package jwt
+
+import "testing"
+
+// TestSymmetricKeySet verifies tokens are signed and verified with the key named by 'kid'
+func TestSymmetricKeySet(t *testing.T) {
+	keys := SymmetricKeySet{
+		"2024-01": []byte("old-secret"),
+		"2024-02": []byte("new-secret"),
+	}
+
+	claims := Claims{Subject: "user123"}
+
+	token, err := keys.Marshal(Header{Alg: HS256}, claims, "2024-02")
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	header, _ := DecodeHeader(token)
+
+	if header.Kid != "2024-02" {
+		t.Errorf("Kid = %q, want %q", header.Kid, "2024-02")
+	}
+
+	var decoded Claims
+
+	if err := keys.Unmarshal(token, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.Subject != claims.Subject {
+		t.Errorf("Subject = %q, want %q", decoded.Subject, claims.Subject)
+	}
+
+	if err := Unmarshal(token, &decoded, []byte("new-secret")); err != nil {
+		t.Errorf("Unmarshal() with the named secret error = %v", err)
+	}
+
+	if err := Unmarshal(token, &decoded, []byte("old-secret")); err != ErrSignatureMismatch {
+		t.Errorf("Unmarshal() with another secret error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+// TestSymmetricKeySetUnknownKeyID verifies unknown key IDs are rejected on both sides
+func TestSymmetricKeySetUnknownKeyID(t *testing.T) {
+	keys := SymmetricKeySet{"current": []byte("secret")}
+
+	if _, err := keys.Marshal(Header{Alg: HS256}, Claims{}, "missing"); err != ErrUnknownKeyID {
+		t.Errorf("Marshal() error = %v, want %v", err, ErrUnknownKeyID)
+	}
+
+	tests := []struct {
+		name   string
+		header Header
+	}{
+		{name: "unknown kid", header: Header{Alg: HS256, Kid: "missing"}},
+		{name: "missing kid", header: Header{Alg: HS256}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(tt.header, Claims{}, []byte("secret"))
+
+			if err := keys.Unmarshal(token, &Claims{}); err != ErrUnknownKeyID {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnknownKeyID)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// MapClaims holds arbitrary claims. Its accessors understand numbers decoded
+// both as float64 and, with WithUseNumber, as json.Number.
+type MapClaims map[string]any
+
+// StringClaim returns the named claim if it is a string. It is not named
+// String so that MapClaims does not look like a fmt.Stringer.
+func (m MapClaims) StringClaim(name string) (string, bool) {
+	s, ok := m[name].(string)
+
+	return s, ok
+}
+
+// Int64 returns the named claim if it is an integer that fits in an int64.
+// Integers beyond 2^53 are only exact when decoded with WithUseNumber.
+func (m MapClaims) Int64(name string) (int64, bool) {
+	switch v := m[name].(type) {
+	case json.Number:
+		n, err := v.Int64()
+
+		return n, err == nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(v), true
+	}
+
+	return 0, false
+}
+
+// Float64 returns the named claim if it is a number.
+func (m MapClaims) Float64(name string) (float64, bool) {
+	switch v := m[name].(type) {
+	case json.Number:
+		f, err := v.Float64()
+
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestWithUseNumber verifies large integers survive decoding into map claims
+func TestWithUseNumber(t *testing.T) {
+	secret := []byte("test-secret")
+	const userID = int64(1<<53 + 1)
+
+	token, _ := Marshal(Header{Alg: HS256}, map[string]any{"user_id": userID}, secret)
+
+	t.Run("json.Number with the option", func(t *testing.T) {
+		var claims MapClaims
+
+		if err := Unmarshal(token, &claims, secret, WithUseNumber()); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if _, ok := claims["user_id"].(json.Number); !ok {
+			t.Fatalf("user_id = %T, want json.Number", claims["user_id"])
+		}
+
+		if got, ok := claims.Int64("user_id"); !ok || got != userID {
+			t.Errorf("Int64() = %v, %v, want %v", got, ok, userID)
+		}
+	})
+
+	t.Run("float64 by default", func(t *testing.T) {
+		var claims MapClaims
+
+		if err := Unmarshal(token, &claims, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if _, ok := claims["user_id"].(float64); !ok {
+			t.Fatalf("user_id = %T, want float64", claims["user_id"])
+		}
+
+		if got, _ := claims.Int64("user_id"); got == userID {
+			t.Errorf("Int64() = %v, want precision loss without WithUseNumber", got)
+		}
+	})
+}
+
+// TestMapClaimsAccessors verifies typed access to map claims
+func TestMapClaimsAccessors(t *testing.T) {
+	claims := MapClaims{
+		"sub":    "user123",
+		"float":  float64(42),
+		"number": json.Number("9007199254740993"),
+		"frac":   1.5,
+		"big":    json.Number("1e30"),
+		"flag":   true,
+	}
+
+	if got, ok := claims.StringClaim("sub"); !ok || got != "user123" {
+		t.Errorf("StringClaim(sub) = %v, %v", got, ok)
+	}
+
+	if _, ok := claims.StringClaim("float"); ok {
+		t.Error("StringClaim(float) ok = true, want false")
+	}
+
+	tests := []struct {
+		name      string
+		wantInt   int64
+		intOK     bool
+		wantFloat float64
+		floatOK   bool
+	}{
+		{name: "float", wantInt: 42, intOK: true, wantFloat: 42, floatOK: true},
+		{name: "number", wantInt: 9007199254740993, intOK: true, wantFloat: 9007199254740993, floatOK: true},
+		{name: "frac", intOK: false, wantFloat: 1.5, floatOK: true},
+		{name: "big", intOK: false, wantFloat: 1e30, floatOK: true},
+		{name: "flag", intOK: false, floatOK: false},
+		{name: "missing", intOK: false, floatOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := claims.Int64(tt.name); ok != tt.intOK || got != tt.wantInt {
+				t.Errorf("Int64() = %v, %v, want %v, %v", got, ok, tt.wantInt, tt.intOK)
+			}
+
+			if got, ok := claims.Float64(tt.name); ok != tt.floatOK || got != tt.wantFloat {
+				t.Errorf("Float64() = %v, %v, want %v, %v", got, ok, tt.wantFloat, tt.floatOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"mime"
+	"strings"
+)
+
+// MediaType is the media type of compact JWTs, registered by RFC 7519 for the
+// HTTP Content-Type of responses holding a bare token.
+const MediaType = "application/jwt"
+
+// ContentTypeForTyp returns the media type a 'typ' header value stands for,
+// such as "application/at+jwt" for "at+jwt". RFC 7515 lets 'typ' omit the
+// "application/" prefix when it holds no '/', so such values are expanded and
+// lowercased; values with a '/' are returned unchanged. An empty 'typ', which
+// Marshal defaults to JWT, yields MediaType.
+func ContentTypeForTyp(typ string) string {
+	if typ == "" {
+		return MediaType
+	}
+
+	if strings.Contains(typ, "/") {
+		return typ
+	}
+
+	return "application/" + strings.ToLower(typ)
+}
+
+// MatchesContentType reports whether a Content-Type header value names the
+// media type of typ, ignoring case and media type parameters such as charset.
+// Malformed header values never match.
+func MatchesContentType(contentType string, typ string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(mediaType, ContentTypeForTyp(typ))
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import "testing"
+
+// TestContentTypeForTyp verifies typ values map to their media types
+func TestContentTypeForTyp(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{typ: "", want: MediaType},
+		{typ: JWT, want: "application/jwt"},
+		{typ: "at+jwt", want: "application/at+jwt"},
+		{typ: "AT+JWT", want: "application/at+jwt"},
+		{typ: "application/at+jwt", want: "application/at+jwt"},
+		{typ: "text/plain", want: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			if got := ContentTypeForTyp(tt.typ); got != tt.want {
+				t.Errorf("ContentTypeForTyp(%q) = %q, want %q", tt.typ, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchesContentType verifies Content-Type values are matched against typ
+func TestMatchesContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		typ         string
+		want        bool
+	}{
+		{name: "exact", contentType: "application/jwt", typ: JWT, want: true},
+		{name: "with parameters", contentType: "application/jwt; charset=utf-8", typ: JWT, want: true},
+		{name: "case-insensitive", contentType: "Application/AT+JWT", typ: "at+jwt", want: true},
+		{name: "full typ", contentType: "application/at+jwt", typ: "application/at+jwt", want: true},
+		{name: "different type", contentType: "application/json", typ: JWT, want: false},
+		{name: "access token against plain JWT", contentType: "application/at+jwt", typ: JWT, want: false},
+		{name: "malformed", contentType: "application/jwt; =", typ: JWT, want: false},
+		{name: "empty", contentType: "", typ: JWT, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesContentType(tt.contentType, tt.typ); got != tt.want {
+				t.Errorf("MatchesContentType(%q, %q) = %v, want %v", tt.contentType, tt.typ, got, tt.want)
+			}
+		})
+	}
+}
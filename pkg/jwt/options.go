@@ -0,0 +1,434 @@
+package jwt
+
+import (
+	"reflect"
+	"time"
+)
+
+// Option configures the behavior of Marshal and Unmarshal.
+type Option func(*options)
+
+type options struct {
+	now            func() time.Time
+	timeUnit       time.Duration
+	leeway         time.Duration
+	autoIssuedAt   bool
+	refreshIat     bool
+	autoNotBefore  bool
+	notBefore      time.Duration
+	omitTyp        bool
+	issuedAtWindow bool
+	limitNotBefore bool
+	maxNotBefore   time.Duration
+	allowExpired   bool
+	zeroExpUnset   bool
+	strictHeaders  bool
+	requireKid     bool
+	lenientBase64  bool
+	lenientSigB64  bool
+	acceptDER      bool
+	useNumber      bool
+	stringyDates   bool
+	claimCipher    *claimCipher
+	validators     []validator
+	headerChecks   []func(Header) error
+	verifyDebug    func(computed, provided []byte)
+	onVerify       func(VerifyEvent)
+	onValid        func(claims any)
+	claimsTarget   any
+	algorithms     []string
+	minHMACBits    int
+	forcedAlg      string
+	ignoreAlgMatch bool
+	types          []string
+	exactType      bool
+	maxAudiences   int
+	signingContext []byte
+
+	issuerNormalization   func(string) string
+	audienceNormalization func(string) string
+	audienceOptional      bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{now: time.Now, timeUnit: time.Second}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithNow fixes the instant used as "now". Marshal uses it for auto-populated
+// time claims and Unmarshal validates exp, nbf, and iat against it. Without
+// this option the real clock is used.
+func WithNow(now time.Time) Option {
+	return func(o *options) {
+		o.now = func() time.Time { return now }
+	}
+}
+
+// WithTimeUnit sets the unit of the numeric exp, nbf, and iat claims, for
+// interoperating with non-compliant issuers that use e.g. time.Millisecond.
+// RFC 7519 NumericDate values are seconds, which is the default. Mixing units
+// is hazardous: a millisecond 'exp' read as seconds lies far in the future,
+// so enable this only for issuers known to need it. unit must divide
+// time.Second; other values are ignored.
+func WithTimeUnit(unit time.Duration) Option {
+	return func(o *options) {
+		if unit > 0 && unit <= time.Second && time.Second%unit == 0 {
+			o.timeUnit = unit
+		}
+	}
+}
+
+// WithLeeway makes Unmarshal tolerate clock skew of up to d between issuer and
+// verifier, uniformly for all three time claims: a token is expired once
+// now - d reaches 'exp', and 'nbf' and 'iat' may lie up to d after now.
+// WithIssuedAtWindow, when given, bounds 'iat' by its own window instead.
+// Negative values are ignored.
+func WithLeeway(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.leeway = d
+		}
+	}
+}
+
+// numericDate returns t as a count of the configured time unit since the epoch.
+func (o *options) numericDate(t time.Time) int64 {
+	return t.Unix()*int64(time.Second/o.timeUnit) + int64(t.Nanosecond())/int64(o.timeUnit)
+}
+
+// fromNumericDate is the inverse of numericDate.
+func (o *options) fromNumericDate(v int64) time.Time {
+	perSecond := int64(time.Second / o.timeUnit)
+
+	return time.Unix(v/perSecond, v%perSecond*int64(o.timeUnit))
+}
+
+// WithAutoIssuedAt makes Marshal set the 'iat' claim to the current time when
+// the claims are or embed Claims and IssuedAt is unset.
+func WithAutoIssuedAt() Option {
+	return func(o *options) {
+		o.autoIssuedAt = true
+	}
+}
+
+// WithNotBefore makes Marshal set the 'nbf' claim to now plus offset, which may
+// be negative to absorb clock skew, when the claims embed Claims and 'nbf' is
+// unset. An explicit 'nbf' is never overwritten.
+func WithNotBefore(offset time.Duration) Option {
+	return func(o *options) {
+		o.autoNotBefore = true
+		o.notBefore = offset
+	}
+}
+
+// WithoutTyp makes Marshal omit the 'typ' header, producing {"alg":"HS256"},
+// for verifiers that reject unexpected header parameters. A Header.Typ given
+// to Marshal is dropped too. Such tokens only pass Unmarshal when the empty
+// type is allowed, e.g. with WithAllowedTypes("").
+func WithoutTyp() Option {
+	return func(o *options) {
+		o.omitTyp = true
+	}
+}
+
+// WithZeroExpiryUnset makes Unmarshal treat an explicit 'exp' claim of 0 as
+// absent, meaning the token never expires. By default such a token is read as
+// expired at the epoch, as RFC 7519 specifies; enable this only for issuers
+// known to send 0 for "no expiry".
+func WithZeroExpiryUnset() Option {
+	return func(o *options) {
+		o.zeroExpUnset = true
+	}
+}
+
+// zeroExpiry reports whether the token holds an explicit 'exp' of 0 that is to
+// be read as the epoch.
+func (o *options) zeroExpiry(t *token) bool {
+	return !o.zeroExpUnset && t.payload.hasZeroExpiry()
+}
+
+// WithForcedAlgorithm makes Unmarshal verify every token with alg, chosen by
+// the server, instead of the algorithm its header declares, defending against
+// 'alg' header manipulation in symmetric-only deployments. A header declaring
+// another algorithm is rejected with ErrUnexpectedAlgorithm unless
+// WithIgnoredAlgorithmMismatch is also given.
+func WithForcedAlgorithm(alg string) Option {
+	return func(o *options) {
+		o.forcedAlg = alg
+	}
+}
+
+// WithIgnoredAlgorithmMismatch makes WithForcedAlgorithm accept tokens whose
+// 'alg' header differs from the forced algorithm, verifying them with the
+// forced algorithm regardless. Without WithForcedAlgorithm it has no effect.
+func WithIgnoredAlgorithmMismatch() Option {
+	return func(o *options) {
+		o.ignoreAlgMatch = true
+	}
+}
+
+// verificationHeader returns the header the signature is verified against:
+// h itself, or h with its algorithm replaced by the forced one.
+func (o *options) verificationHeader(h Header) (Header, error) {
+	if o.forcedAlg == "" {
+		return h, nil
+	}
+
+	if h.Alg != o.forcedAlg && !o.ignoreAlgMatch {
+		return Header{}, ErrUnexpectedAlgorithm
+	}
+
+	h.Alg = o.forcedAlg
+
+	return h, nil
+}
+
+// WithDisallowUnknownHeaders makes Unmarshal reject tokens whose header holds
+// parameters that Header does not model. It is off by default.
+func WithDisallowUnknownHeaders() Option {
+	return func(o *options) {
+		o.strictHeaders = true
+	}
+}
+
+// WithRequireKid makes Unmarshal reject tokens without a 'kid' header with
+// ErrMissingKeyID while the header is parsed, before the key is resolved. It is
+// independent of key resolution, which may still fall back to a default key.
+// It is off by default.
+func WithRequireKid() Option {
+	return func(o *options) {
+		o.requireKid = true
+	}
+}
+
+// WithHeaderValidator makes Unmarshal pass the decoded header to fn before the
+// signature is verified, so unexpected headers are rejected without computing
+// a signature. A non-nil error from fn is returned verbatim. The header is not
+// yet authenticated when fn runs, so fn may only reject tokens, never grant
+// trust. Several header validators run in the order given.
+func WithHeaderValidator(fn func(Header) error) Option {
+	return func(o *options) {
+		o.headerChecks = append(o.headerChecks, fn)
+	}
+}
+
+// WithLenientBase64 makes Unmarshal accept segments carrying trailing '='
+// padding or the standard '+' and '/' base64 characters, as emitted by some
+// non-compliant libraries. The segments are normalized to unpadded base64url
+// before the signing input is rebuilt and verified, so the signature must
+// still cover the canonical form.
+func WithLenientBase64() Option {
+	return func(o *options) {
+		o.lenientBase64 = true
+	}
+}
+
+// WithLenientSignatureB64 makes Unmarshal also accept a signature segment in
+// standard base64, with '+', '/', and optional '=' padding, as produced by some
+// non-compliant signers. Unlike WithLenientBase64 the header and payload must
+// still be strict base64url; they form the signing input unchanged, so only
+// the way the signature bytes are read differs.
+func WithLenientSignatureB64() Option {
+	return func(o *options) {
+		o.lenientSigB64 = true
+	}
+}
+
+// WithAcceptDERSignatures makes Unmarshal also accept ES256, ES384, and ES512
+// signatures in ASN.1 DER, as emitted by some non-compliant issuers, instead of
+// the fixed-width R||S encoding of RFC 7518. A signature of the R||S length is
+// always tried as R||S first, and only the canonical DER encoding is accepted,
+// so each signature still has a single valid form. Marshal always signs in R||S.
+func WithAcceptDERSignatures() Option {
+	return func(o *options) {
+		o.acceptDER = true
+	}
+}
+
+// WithSigningContext binds context into the signature on Marshal and requires
+// the same context on Unmarshal, e.g. a channel-binding value for
+// proof-of-possession. The context is appended to the signing input after a
+// '.' separator and is never transmitted in the token. This is a non-standard
+// extension: standard JWT verifiers cannot verify such tokens.
+func WithSigningContext(context []byte) Option {
+	return func(o *options) {
+		o.signingContext = append([]byte(nil), context...)
+	}
+}
+
+// WithUseNumber makes Unmarshal decode numbers held in interface values, such
+// as those of map claims, as json.Number instead of float64, preserving the
+// precision of integers beyond 2^53.
+func WithUseNumber() Option {
+	return func(o *options) {
+		o.useNumber = true
+	}
+}
+
+// WithStringyDates makes Unmarshal accept 'exp', 'nbf', and 'iat' claims
+// encoded as JSON strings holding an integer, such as "1700000000", as emitted
+// by some non-compliant issuers. Strings that are not integers fail with
+// ErrMalformedClaims. RFC 7519 requires numbers, so by default strings are
+// rejected; enable this only while migrating off such an issuer.
+func WithStringyDates() Option {
+	return func(o *options) {
+		o.stringyDates = true
+	}
+}
+
+// WithVerifyDebug makes Unmarshal call fn with copies of the computed and the
+// provided signatures when they do not match, to diagnose key configuration
+// errors. It is meant for controlled debugging environments: fn must never log
+// the raw bytes, only a redacted form such as RedactSignature. The comparison
+// itself remains constant-time and the result is still ErrSignatureMismatch.
+// Signatures whose length does not fit the algorithm are rejected before any
+// signature is computed, so fn is not called for them. Only HMAC signatures can
+// be recomputed from the verification key, so fn is not called for RSA or ECDSA.
+func WithVerifyDebug(fn func(computed, provided []byte)) Option {
+	return func(o *options) {
+		o.verifyDebug = fn
+	}
+}
+
+// VerifyEvent describes one verification attempt, for metrics and load control.
+type VerifyEvent struct {
+	// Alg is the algorithm the signature was verified with: the 'alg' header,
+	// or the algorithm of WithForcedAlgorithm; empty when the header could not
+	// be decoded
+	Alg string
+
+	// Duration is the time spent computing and comparing the signature, zero
+	// when the token was rejected before that
+	Duration time.Duration
+
+	// Err is the verification result, nil when the token was accepted
+	Err error
+}
+
+// WithOnVerify makes Unmarshal call fn once per verification attempt, whatever
+// its outcome, with the algorithm and the signature verification time measured
+// on the real clock. fn runs synchronously and cannot change the result.
+func WithOnVerify(fn func(VerifyEvent)) Option {
+	return func(o *options) {
+		o.onVerify = fn
+	}
+}
+
+// WithOnValid makes Unmarshal call fn with the caller's claims once the token
+// has been fully verified and validated, for side effects such as usage
+// metering. fn is never called for a rejected token, runs synchronously, and
+// cannot change the result.
+func WithOnValid(fn func(claims any)) Option {
+	return func(o *options) {
+		o.onValid = fn
+	}
+}
+
+// WithClaimsTarget makes Parse decode the claims into target, which must be a
+// pointer as accepted by Unmarshal, instead of a map[string]any.
+func WithClaimsTarget(target any) Option {
+	return func(o *options) {
+		o.claimsTarget = target
+	}
+}
+
+// prepareClaims applies the marshal-side options to a copy of the claims.
+func (o *options) prepareClaims(claims any) any {
+	if !o.autoIssuedAt && !o.autoNotBefore {
+		return claims
+	}
+
+	now := o.now()
+
+	return withRegisteredClaims(claims, func(c *Claims) {
+		if o.autoIssuedAt && c.IssuedAt == 0 {
+			c.IssuedAt = o.numericDate(now)
+		}
+
+		if o.autoNotBefore && c.NotBefore == 0 {
+			c.NotBefore = o.numericDate(now.Add(o.notBefore))
+		}
+	})
+}
+
+var claimsType = reflect.TypeOf(Claims{})
+
+// withRegisteredClaims passes the registered claims of a copy of claims to fn
+// and returns the copy, leaving the caller's value untouched. Values that are
+// not and do not embed Claims are returned unchanged.
+func withRegisteredClaims(claims any, fn func(*Claims)) any {
+	v := reflect.ValueOf(claims)
+
+	if !v.IsValid() {
+		return claims
+	}
+
+	isPtr := v.Kind() == reflect.Ptr
+
+	if isPtr {
+		if v.IsNil() {
+			return claims
+		}
+
+		v = v.Elem()
+	}
+
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+
+	c := findClaims(cp)
+
+	if c == nil {
+		return claims
+	}
+
+	fn(c)
+
+	if isPtr {
+		return cp.Addr().Interface()
+	}
+
+	return cp.Interface()
+}
+
+// findClaims locates the registered claims in an addressable value that is or
+// directly embeds Claims. An embedded *Claims is replaced by a copy so that
+// changes never reach the caller.
+func findClaims(v reflect.Value) *Claims {
+	if v.Type() == claimsType {
+		return v.Addr().Interface().(*Claims)
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if !field.Anonymous {
+			continue
+		}
+
+		switch field.Type {
+		case claimsType:
+			return v.Field(i).Addr().Interface().(*Claims)
+		case reflect.PtrTo(claimsType):
+			if v.Field(i).IsNil() {
+				return nil
+			}
+
+			c := *v.Field(i).Interface().(*Claims)
+			v.Field(i).Set(reflect.ValueOf(&c))
+
+			return &c
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,933 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithNowValidation verifies Unmarshal validates time claims against a fixed instant
+func TestWithNowValidation(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	issued := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	claims := Claims{
+		IssuedAt:  issued.Unix(),
+		NotBefore: issued.Unix(),
+		ExpiresAt: issued.Add(1 * time.Hour).Unix(),
+	}
+
+	token, err := Marshal(header, claims, secret)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:    "within validity window",
+			now:     issued.Add(30 * time.Minute),
+			wantErr: nil,
+		},
+		{
+			name:    "after expiration",
+			now:     issued.Add(2 * time.Hour),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "before not before",
+			now:     issued.Add(-1 * time.Minute),
+			wantErr: ErrTokenNotValidYet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Claims
+
+			err := Unmarshal(token, &decoded, secret, WithNow(tt.now))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("defaults to the real clock", func(t *testing.T) {
+		var decoded Claims
+
+		if err := Unmarshal(token, &decoded, secret); err != ErrTokenExpired {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+		}
+	})
+}
+
+// TestWithTimeUnit verifies millisecond time claims are validated in the configured unit
+func TestWithTimeUnit(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	issued := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	claims := Claims{
+		IssuedAt:  issued.UnixNano() / int64(time.Millisecond),
+		ExpiresAt: issued.Add(1*time.Hour).UnixNano() / int64(time.Millisecond),
+	}
+
+	token, _ := Marshal(header, claims, secret)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "milliseconds within validity window",
+			now:     issued.Add(30 * time.Minute),
+			opts:    []Option{WithTimeUnit(time.Millisecond)},
+			wantErr: nil,
+		},
+		{
+			name:    "milliseconds after expiration",
+			now:     issued.Add(2 * time.Hour),
+			opts:    []Option{WithTimeUnit(time.Millisecond)},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "seconds by default",
+			now:     issued.Add(30 * time.Minute),
+			wantErr: ErrTokenUsedBeforeIssued,
+		},
+		{
+			name:    "unsupported unit is ignored",
+			now:     issued.Add(30 * time.Minute),
+			opts:    []Option{WithTimeUnit(time.Minute)},
+			wantErr: ErrTokenUsedBeforeIssued,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Claims
+
+			err := Unmarshal(token, &decoded, secret, append(tt.opts, WithNow(tt.now))...)
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("scales ExpiresWithin", func(t *testing.T) {
+		opts := []Option{WithTimeUnit(time.Millisecond), WithNow(issued)}
+
+		if !claims.ExpiresWithin(2*time.Hour, opts...) || claims.ExpiresWithin(30*time.Minute, opts...) {
+			t.Error("ExpiresWithin() did not honor the time unit")
+		}
+	})
+}
+
+// TestWithAutoIssuedAt verifies Marshal populates iat from the configured clock
+func TestWithAutoIssuedAt(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	type CustomClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	t.Run("reproducible tokens with a fixed clock", func(t *testing.T) {
+		claims := Claims{Subject: "user123"}
+
+		first, err := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		second, err := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		if first != second {
+			t.Errorf("Marshal() produced different tokens: %q and %q", first, second)
+		}
+
+		var decoded Claims
+
+		if err := Unmarshal(first, &decoded, secret, WithNow(now)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.IssuedAt != now.Unix() {
+			t.Errorf("IssuedAt = %v, want %v", decoded.IssuedAt, now.Unix())
+		}
+	})
+
+	t.Run("explicit iat is preserved", func(t *testing.T) {
+		claims := Claims{IssuedAt: now.Add(-1 * time.Hour).Unix()}
+
+		token, _ := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+
+		var decoded Claims
+
+		if err := Unmarshal(token, &decoded, secret, WithNow(now)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.IssuedAt != claims.IssuedAt {
+			t.Errorf("IssuedAt = %v, want %v", decoded.IssuedAt, claims.IssuedAt)
+		}
+	})
+
+	t.Run("embedded claims via pointer are not mutated", func(t *testing.T) {
+		claims := &CustomClaims{Role: "admin"}
+
+		token, _ := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+
+		if claims.IssuedAt != 0 {
+			t.Errorf("caller claims mutated: IssuedAt = %v", claims.IssuedAt)
+		}
+
+		var decoded CustomClaims
+
+		if err := Unmarshal(token, &decoded, secret, WithNow(now)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.IssuedAt != now.Unix() || decoded.Role != "admin" {
+			t.Errorf("decoded = %+v, want iat %v and role admin", decoded, now.Unix())
+		}
+	})
+
+	t.Run("claims without registered claims are unchanged", func(t *testing.T) {
+		claims := map[string]any{"sub": "user123"}
+
+		token, _ := Marshal(header, claims, secret, WithNow(now), WithAutoIssuedAt())
+
+		var decoded map[string]any
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if _, ok := decoded["iat"]; ok {
+			t.Errorf("iat should not be set on map claims: %v", decoded)
+		}
+	})
+}
+
+// TestWithNotBefore verifies Marshal populates nbf at an offset from the configured clock
+func TestWithNotBefore(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		claims Claims
+		offset time.Duration
+		want   int64
+	}{
+		{
+			name:   "at issue time",
+			claims: Claims{Subject: "user123"},
+			offset: 0,
+			want:   now.Unix(),
+		},
+		{
+			name:   "negative offset absorbs skew",
+			claims: Claims{Subject: "user123"},
+			offset: -30 * time.Second,
+			want:   now.Add(-30 * time.Second).Unix(),
+		},
+		{
+			name:   "positive offset",
+			claims: Claims{Subject: "user123"},
+			offset: time.Minute,
+			want:   now.Add(time.Minute).Unix(),
+		},
+		{
+			name:   "explicit nbf is preserved",
+			claims: Claims{NotBefore: now.Add(-time.Hour).Unix()},
+			offset: 0,
+			want:   now.Add(-time.Hour).Unix(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret, WithNow(now), WithNotBefore(tt.offset))
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var decoded Claims
+
+			if _, err := ParseUnverified(token, &decoded); err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+
+			if decoded.NotBefore != tt.want {
+				t.Errorf("NotBefore = %v, want %v", decoded.NotBefore, tt.want)
+			}
+		})
+	}
+
+	t.Run("pairs with WithAutoIssuedAt", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{}, secret, WithNow(now), WithAutoIssuedAt(), WithNotBefore(0))
+
+		var decoded Claims
+
+		if err := Unmarshal(token, &decoded, secret, WithNow(now)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.IssuedAt != now.Unix() || decoded.NotBefore != now.Unix() {
+			t.Errorf("decoded = %+v, want iat and nbf %v", decoded, now.Unix())
+		}
+	})
+}
+
+// TestWithoutTyp verifies Marshal can omit the 'typ' header entirely
+func TestWithoutTyp(t *testing.T) {
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name   string
+		header Header
+		opts   []Option
+		want   string
+	}{
+		{
+			name:   "default typ",
+			header: Header{Alg: HS256},
+			want:   `{"alg":"HS256","typ":"JWT"}`,
+		},
+		{
+			name:   "without typ",
+			header: Header{Alg: HS256},
+			opts:   []Option{WithoutTyp()},
+			want:   `{"alg":"HS256"}`,
+		},
+		{
+			name:   "drops an explicit typ",
+			header: Header{Alg: HS256, Typ: "at+jwt", Kid: "key-1"},
+			opts:   []Option{WithoutTyp()},
+			want:   `{"alg":"HS256","kid":"key-1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(tt.header, Claims{}, secret, tt.opts...)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			header, _ := decodeJWTBase64(strings.Split(token, ".")[0])
+
+			if string(header) != tt.want {
+				t.Errorf("header = %s, want %s", header, tt.want)
+			}
+		})
+	}
+
+	t.Run("verifies when the empty type is allowed", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{}, secret, WithoutTyp())
+
+		if err := Unmarshal(token, &Claims{}, secret); err != (unsupportedTypeError{}) {
+			t.Errorf("Unmarshal() error = %v, want %v", err, unsupportedTypeError{})
+		}
+
+		if err := Unmarshal(token, &Claims{}, secret, WithAllowedTypes("")); err != nil {
+			t.Errorf("Unmarshal() with WithAllowedTypes(\"\") error = %v", err)
+		}
+	})
+}
+
+// TestWithDisallowUnknownHeaders verifies strict header decoding
+func TestWithDisallowUnknownHeaders(t *testing.T) {
+	secret := []byte("test-secret")
+	payload := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+
+	sign := func(header string) string {
+		encoded := encodeJWTBase64([]byte(header))
+		signature, _ := ComputeSignature(encoded+"."+payload, HS256, secret)
+		return encoded + "." + payload + "." + signature
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		strictErr  bool
+		lenientErr bool
+	}{
+		{
+			name:   "modeled fields only",
+			header: `{"alg":"HS256","typ":"JWT"}`,
+		},
+		{
+			name:      "unknown parameter",
+			header:    `{"alg":"HS256","typ":"JWT","x-vendor":"abc"}`,
+			strictErr: true,
+		},
+		{
+			name:       "trailing data",
+			header:     `{"alg":"HS256","typ":"JWT"} {}`,
+			strictErr:  true,
+			lenientErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := sign(tt.header)
+
+			err := Unmarshal(token, &Claims{}, secret, WithDisallowUnknownHeaders())
+
+			if (err != nil) != tt.strictErr {
+				t.Errorf("strict Unmarshal() error = %v, wantErr %v", err, tt.strictErr)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret)
+
+			if (err != nil) != tt.lenientErr {
+				t.Errorf("default Unmarshal() error = %v, wantErr %v", err, tt.lenientErr)
+			}
+		})
+	}
+}
+
+// TestWithRequireKid verifies tokens without a kid header are rejected
+func TestWithRequireKid(t *testing.T) {
+	secret := []byte("test-secret")
+	keys := SymmetricKeySet{"2024-01": secret}
+
+	withKid, _ := keys.Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, "2024-01")
+	withoutKid, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		key     any
+		opts    []Option
+		wantErr error
+	}{
+		{name: "kid present", token: withKid, key: keys, opts: []Option{WithRequireKid()}},
+		{name: "kid present with a default key", token: withKid, key: secret, opts: []Option{WithRequireKid()}},
+		{name: "kid missing", token: withoutKid, key: secret, opts: []Option{WithRequireKid()}, wantErr: ErrMissingKeyID},
+		{name: "checked before the signature", token: withoutKid, key: []byte("other"), opts: []Option{WithRequireKid()}, wantErr: ErrMissingKeyID},
+		{name: "default is permissive", token: withoutKid, key: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, tt.key, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithVerifyDebug verifies the debug callback only fires on signature mismatch
+func TestWithVerifyDebug(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	var computed, provided []byte
+
+	calls := 0
+	debug := WithVerifyDebug(func(c, p []byte) {
+		calls++
+		computed, provided = c, p
+	})
+
+	if err := Unmarshal(token, &Claims{}, secret, debug); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("callback called %d times on a valid token", calls)
+	}
+
+	if err := Unmarshal(token, &Claims{}, []byte("rotated-secret"), debug); err != ErrSignatureMismatch {
+		t.Fatalf("Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	if calls != 1 || len(computed) != 32 || len(provided) != 32 {
+		t.Errorf("calls = %d, computed %d bytes, provided %d bytes", calls, len(computed), len(provided))
+	}
+
+	if RedactSignature(computed) == RedactSignature(provided) {
+		t.Error("computed and provided signatures should differ")
+	}
+}
+
+// TestWithHeaderValidator verifies header checks run before the signature
+func TestWithHeaderValidator(t *testing.T) {
+	secret := []byte("test-secret")
+	errKid := errors.New("unexpected key id")
+
+	kidPrefix := WithHeaderValidator(func(h Header) error {
+		if !strings.HasPrefix(h.Kid, "prod-") {
+			return errKid
+		}
+
+		return nil
+	})
+
+	prod, _ := Marshal(Header{Alg: HS256, Kid: "prod-1"}, Claims{Subject: "user123"}, secret)
+	staging, _ := Marshal(Header{Alg: HS256, Kid: "staging-1"}, Claims{Subject: "user123"}, secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		key     []byte
+		opts    []Option
+		wantErr error
+	}{
+		{name: "accepted header", token: prod, key: secret, opts: []Option{kidPrefix}},
+		{name: "rejected header", token: staging, key: secret, opts: []Option{kidPrefix}, wantErr: errKid},
+		{name: "checked before the signature", token: staging, key: []byte("other"), opts: []Option{kidPrefix}, wantErr: errKid},
+		{name: "signature still verified", token: prod, key: []byte("other"), opts: []Option{kidPrefix}, wantErr: ErrSignatureMismatch},
+		{name: "no check by default", token: staging, key: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, tt.key, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("validators run in order", func(t *testing.T) {
+		var calls []string
+
+		record := func(name string) Option {
+			return WithHeaderValidator(func(Header) error {
+				calls = append(calls, name)
+				return nil
+			})
+		}
+
+		if err := Unmarshal(prod, &Claims{}, secret, record("first"), record("second")); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if strings.Join(calls, ",") != "first,second" {
+			t.Errorf("calls = %v, want [first second]", calls)
+		}
+	})
+}
+
+// TestWithForcedAlgorithm verifies the server-chosen algorithm overrides the header
+func TestWithForcedAlgorithm(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+
+	// forge signs claims with alg while declaring declared in the header
+	forge := func(declared, alg string) string {
+		header := encodeJWTBase64([]byte(`{"alg":"` + declared + `","typ":"JWT"}`))
+		signature, _ := ComputeSignature(header+"."+claims, alg, secret)
+		return header + "." + claims + "." + signature
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:  "matching header",
+			token: forge(HS256, HS256),
+			opts:  []Option{WithForcedAlgorithm(HS256)},
+		},
+		{
+			name:    "mismatching header rejected",
+			token:   forge(HS512, HS512),
+			opts:    []Option{WithForcedAlgorithm(HS256)},
+			wantErr: ErrUnexpectedAlgorithm,
+		},
+		{
+			name:  "mismatch ignored",
+			token: forge("none", HS256),
+			opts:  []Option{WithForcedAlgorithm(HS256), WithIgnoredAlgorithmMismatch()},
+		},
+		{
+			name:    "header algorithm never used",
+			token:   forge(HS512, HS512),
+			opts:    []Option{WithForcedAlgorithm(HS256), WithIgnoredAlgorithmMismatch()},
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:  "ignoring has no effect alone",
+			token: forge(HS512, HS512),
+			opts:  []Option{WithIgnoredAlgorithmMismatch()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("diagnosed like Unmarshal", func(t *testing.T) {
+		_, errs := Diagnose(forge(HS512, HS256), secret, WithForcedAlgorithm(HS256))
+
+		if len(errs) != 1 || errs[0] != ErrUnexpectedAlgorithm {
+			t.Errorf("Diagnose() = %v, want [%v]", errs, ErrUnexpectedAlgorithm)
+		}
+	})
+}
+
+// TestWithLenientBase64 verifies padded and standard-alphabet segments verify once normalized
+func TestWithLenientBase64(t *testing.T) {
+	key, _ := base64.RawURLEncoding.DecodeString(rfc7515HS256.key)
+	now := WithNow(time.Unix(1300819300, 0))
+
+	// The RFC 7515 A.1 token as padded by encoders such as Python's
+	// base64.urlsafe_b64encode: the payload takes "==" and the signature "="
+	padded := rfc7515HS256.header + "." + rfc7515HS256.payload + "==." + rfc7515HS256.signature + "="
+
+	// A payload whose encoding uses the '-' and '_' characters, re-encoded
+	// with the standard alphabet after signing the canonical form
+	header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := encodeJWTBase64([]byte(`{"sub":"?>?~"}`))
+	signature, _ := ComputeSignature(header+"."+payload, HS256, key)
+	standard := strings.NewReplacer("-", "+", "_", "/").Replace(header + "." + payload + "." + signature)
+
+	if !strings.ContainsAny(standard, "+/") {
+		t.Fatalf("token %q does not exercise the standard alphabet", standard)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "padded token",
+			token:   padded,
+			opts:    []Option{now, WithLenientBase64()},
+			wantErr: nil,
+		},
+		{
+			name:    "standard alphabet",
+			token:   standard,
+			opts:    []Option{WithLenientBase64()},
+			wantErr: nil,
+		},
+		{
+			name:    "canonical token",
+			token:   rfc7515HS256.header + "." + rfc7515HS256.payload + "." + rfc7515HS256.signature,
+			opts:    []Option{now, WithLenientBase64()},
+			wantErr: nil,
+		},
+		{
+			name:    "padded token rejected by default",
+			token:   padded,
+			opts:    []Option{now},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "standard alphabet rejected by default",
+			token:   standard,
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded map[string]any
+
+			if err := Unmarshal(tt.token, &decoded, key, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("signing input uses the normalized segments", func(t *testing.T) {
+		info, err := Parse(padded, key, now, WithLenientBase64())
+
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if want := rfc7515HS256.header + "." + rfc7515HS256.payload; info.SigningInput != want {
+			t.Errorf("SigningInput = %q, want %q", info.SigningInput, want)
+		}
+	})
+}
+
+// TestWithLenientSignatureB64 verifies only the signature may use standard base64
+func TestWithLenientSignatureB64(t *testing.T) {
+	secret := []byte("test-secret")
+	toStandard := strings.NewReplacer("-", "+", "_", "/")
+
+	// Find a token whose signature exercises the characters that differ
+	var token string
+
+	for i := 0; !strings.ContainsAny(token[strings.LastIndex(token, ".")+1:], "-_"); i++ {
+		token, _ = Marshal(Header{Alg: HS256}, Claims{Subject: "user" + strconv.Itoa(i)}, secret)
+	}
+
+	dot := strings.LastIndex(token, ".")
+	standardSignature := token[:dot+1] + toStandard.Replace(token[dot+1:]) + "="
+
+	// A payload whose encoding uses the '-' and '_' characters
+	header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := encodeJWTBase64([]byte(`{"sub":"?>?~"}`))
+	signature, _ := ComputeSignature(header+"."+payload, HS256, secret)
+	standardPayload := header + "." + toStandard.Replace(payload) + "." + signature
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "standard signature",
+			token:   standardSignature,
+			opts:    []Option{WithLenientSignatureB64()},
+			wantErr: nil,
+		},
+		{
+			name:    "canonical token",
+			token:   token,
+			opts:    []Option{WithLenientSignatureB64()},
+			wantErr: nil,
+		},
+		{
+			name:    "standard payload still rejected",
+			token:   standardPayload,
+			opts:    []Option{WithLenientSignatureB64()},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "standard signature rejected by default",
+			token:   standardSignature,
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithOnVerify verifies one event is reported per attempt with its outcome
+func TestWithOnVerify(t *testing.T) {
+	secret := []byte("test-secret")
+
+	valid, _ := Marshal(Header{Alg: HS384}, Claims{Subject: "user123"}, secret)
+	expired, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: 1}, secret)
+
+	tests := []struct {
+		name         string
+		token        string
+		key          []byte
+		wantAlg      string
+		wantErr      error
+		wantDuration bool
+	}{
+		{name: "valid token", token: valid, key: secret, wantAlg: HS384, wantDuration: true},
+		{name: "wrong secret", token: valid, key: []byte("other"), wantAlg: HS384, wantErr: ErrSignatureMismatch, wantDuration: true},
+		{name: "validation failure", token: expired, key: secret, wantAlg: HS256, wantErr: ErrTokenExpired, wantDuration: true},
+		{name: "malformed token", token: "a.b", key: secret, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []VerifyEvent
+
+			err := Unmarshal(tt.token, &Claims{}, tt.key, WithOnVerify(func(e VerifyEvent) {
+				events = append(events, e)
+			}))
+
+			if err != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+
+			e := events[0]
+
+			if e.Alg != tt.wantAlg || e.Err != tt.wantErr {
+				t.Errorf("event = %+v, want alg %q and error %v", e, tt.wantAlg, tt.wantErr)
+			}
+
+			if (e.Duration > 0) != tt.wantDuration {
+				t.Errorf("event duration = %v, want measured %v", e.Duration, tt.wantDuration)
+			}
+		})
+	}
+
+	t.Run("forced algorithm", func(t *testing.T) {
+		header := encodeJWTBase64([]byte(`{"alg":"HS512","typ":"JWT"}`))
+		claims := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+		signature, _ := ComputeSignature(header+"."+claims, HS256, secret)
+
+		var events []VerifyEvent
+
+		opts := []Option{
+			WithForcedAlgorithm(HS256),
+			WithIgnoredAlgorithmMismatch(),
+			WithOnVerify(func(e VerifyEvent) { events = append(events, e) }),
+		}
+
+		if err := Unmarshal(header+"."+claims+"."+signature, &Claims{}, secret, opts...); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if len(events) != 1 || events[0].Alg != HS256 {
+			t.Errorf("events = %+v, want one with alg %q", events, HS256)
+		}
+
+		if alg, err := VerifiedAlgorithm(header+"."+claims+"."+signature, secret, opts...); alg != HS256 || err != nil {
+			t.Errorf("VerifiedAlgorithm() = %q, %v, want %q", alg, err, HS256)
+		}
+	})
+}
+
+// TestWithOnValid verifies the hook only sees the claims of accepted tokens
+func TestWithOnValid(t *testing.T) {
+	secret := []byte("test-secret")
+
+	valid, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+	expired, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123", ExpiresAt: 1}, secret)
+
+	tests := []struct {
+		name     string
+		token    string
+		key      []byte
+		opts     []Option
+		wantCall bool
+	}{
+		{name: "valid token", token: valid, key: secret, wantCall: true},
+		{name: "wrong secret", token: valid, key: []byte("other")},
+		{name: "expired token", token: expired, key: secret},
+		{name: "failed validator", token: valid, key: secret, opts: []Option{WithIssuer("issuer")}},
+		{name: "malformed token", token: "a.b.c", key: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Claims
+			var got []any
+
+			opts := append(tt.opts, WithOnValid(func(claims any) {
+				got = append(got, claims)
+			}))
+
+			_ = Unmarshal(tt.token, &decoded, tt.key, opts...)
+
+			if !tt.wantCall {
+				if len(got) != 0 {
+					t.Errorf("hook called %d times for a rejected token", len(got))
+				}
+
+				return
+			}
+
+			if len(got) != 1 {
+				t.Fatalf("hook called %d times, want 1", len(got))
+			}
+
+			if claims, ok := got[0].(*Claims); !ok || claims != &decoded || claims.Subject != "user123" {
+				t.Errorf("hook got %#v, want the caller's decoded claims", got[0])
+			}
+		})
+	}
+
+	t.Run("called by Parse", func(t *testing.T) {
+		calls := 0
+
+		if _, err := Parse(valid, secret, WithOnValid(func(any) { calls++ })); err != nil || calls != 1 {
+			t.Errorf("Parse() error = %v with %d calls, want nil with 1", err, calls)
+		}
+	})
+}
+
+// TestWithSigningContext verifies both sides must share the signing context
+func TestWithSigningContext(t *testing.T) {
+	secret := []byte("test-secret")
+	binding := []byte("tls-exporter:0123456789")
+
+	token, err := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret, WithSigningContext(binding))
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if strings.Contains(token, encodeJWTBase64(binding)) {
+		t.Error("signing context leaked into the token")
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "same context",
+			opts:    []Option{WithSigningContext(binding)},
+			wantErr: nil,
+		},
+		{
+			name:    "different context",
+			opts:    []Option{WithSigningContext([]byte("tls-exporter:other"))},
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "no context",
+			opts:    nil,
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("standard tokens need no context", func(t *testing.T) {
+		standard, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+		if err := Unmarshal(standard, &Claims{}, secret, WithSigningContext(binding)); err != ErrSignatureMismatch {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+		}
+
+		if err := Unmarshal(standard, &Claims{}, secret, WithSigningContext(nil)); err != nil {
+			t.Errorf("Unmarshal() with an empty context error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// TokenInfo describes a token processed by Parse.
+type TokenInfo struct {
+	// Header is the decoded token header
+	Header Header
+
+	// Claims holds the decoded claims: the WithClaimsTarget value, or a
+	// *map[string]any by default
+	Claims any
+
+	// Valid reports whether the signature verified and all validation passed
+	Valid bool
+
+	// SigningInput is the exact "header.payload" string the signature covers
+	SigningInput string
+
+	// RawHeader, RawPayload, and RawSignature are the encoded segments
+	RawHeader, RawPayload, RawSignature string
+}
+
+// Parse verifies and validates a JWT like Unmarshal and describes the result.
+// When the token is well-formed but fails verification or validation, Parse
+// returns both the TokenInfo, with Valid false, and the error; the claims are
+// only populated once the signature has been verified.
+func Parse(jws string, key any, opts ...Option) (*TokenInfo, error) {
+	o := newOptions(opts)
+
+	claims := o.claimsTarget
+
+	if claims == nil {
+		claims = &map[string]any{}
+	}
+
+	t := &token{
+		payload: payload{claims: claims},
+	}
+
+	err := t.verify(jws, key, o)
+
+	if err == nil && o.onValid != nil {
+		o.onValid(claims)
+	}
+
+	if t.raw == (b64values{}) {
+		return nil, err
+	}
+
+	info := &TokenInfo{
+		Header:       t.header,
+		Claims:       claims,
+		Valid:        err == nil,
+		SigningInput: t.signingInput(t.raw.header, t.raw.payload),
+		RawHeader:    t.raw.header,
+		RawPayload:   t.raw.payload,
+		RawSignature: t.raw.signature,
+	}
+
+	return info, err
+}
+
+// Validate reports whether jws is structurally a JWT without verifying its
+// signature: three base64url segments, a header and a payload that decode to
+// JSON objects, a supported 'alg' other than the unsigned "none", and a
+// signature as long as that algorithm produces. Any problem yields
+// ErrInvalidToken. A nil error says nothing about authenticity; it only makes
+// Validate a cheap pre-filter for untrusted input.
+func Validate(jws string) error {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return ErrInvalidToken
+	}
+
+	var header Header
+
+	if !isJSONObject(b64vals.header) || header.unmarshal(b64vals.header) != nil || !isJSONObject(b64vals.payload) {
+		return ErrInvalidToken
+	}
+
+	method, err := methodFor(header.Alg, nil)
+
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	signature, err := decodeJWTBase64(b64vals.signature)
+
+	if err != nil || method.checkLength(len(signature)) != nil {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// isJSONObject reports whether a segment decodes to a JSON object.
+func isJSONObject(encoded string) bool {
+	decoded, err := decodeJWTBase64(encoded)
+
+	if err != nil {
+		return false
+	}
+
+	var object map[string]json.RawMessage
+
+	return json.Unmarshal(decoded, &object) == nil && object != nil
+}
+
+// DecodeHeader decodes the header of a JWS without verifying its signature.
+// The returned header is untrusted and must not be used for security decisions.
+func DecodeHeader(jws string) (Header, error) {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return Header{}, err
+	}
+
+	header := Header{}
+
+	if err := header.unmarshal(b64vals.header); err != nil {
+		return Header{}, err
+	}
+
+	return header, nil
+}
+
+// SigningInputFromToken returns the signing input of an existing token: its
+// encoded header and payload joined by '.', exactly as received. It is meant
+// for external verifiers checking the signature themselves. Tokens without
+// three base64url segments, or with an empty header or payload, yield
+// ErrInvalidToken. Nothing is decoded or verified.
+func SigningInputFromToken(jws string) (string, error) {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return "", err
+	}
+
+	if b64vals.header == "" || b64vals.payload == "" {
+		return "", ErrInvalidToken
+	}
+
+	if !isBase64URL(b64vals.header) || !isBase64URL(b64vals.payload) || !isBase64URL(b64vals.signature) {
+		return "", ErrInvalidToken
+	}
+
+	return b64vals.header + "." + b64vals.payload, nil
+}
+
+// ParseUnverified decodes the header and claims of a JWS without verifying its
+// signature or validating its claims, for services that read tokens signed by
+// keys they do not hold, such as logging the 'iss' and 'sub' claims. The token
+// must still have three segments of base64url-encoded JSON objects, otherwise
+// ErrInvalidToken is returned. The returned header reports Unverified, and the
+// decoded data is untrusted: it must never be used to make access decisions.
+func ParseUnverified(jws string, claims any) (Header, error) {
+	b64vals := b64values{}
+
+	// The signature is not decoded, but a fourth segment or stray characters
+	// in it still make the token malformed
+	if err := b64vals.unmarshal(jws); err != nil || !isBase64URL(b64vals.signature) {
+		return Header{}, ErrInvalidToken
+	}
+
+	header := Header{}
+
+	if err := header.unmarshal(b64vals.header); err != nil || !isJSONObject(b64vals.payload) {
+		return Header{}, ErrInvalidToken
+	}
+
+	p := payload{claims: claims}
+
+	if err := p.unmarshal(b64vals.payload); err != nil {
+		if err == ErrNilClaimsTarget {
+			return Header{}, err
+		}
+
+		return Header{}, ErrInvalidToken
+	}
+
+	header.unverified = true
+
+	return header, nil
+}
+
+// SameClaims reports whether two tokens carry equivalent claims, regardless of
+// their headers and signatures, e.g. the same claims re-signed with a rotated
+// key. The payloads are compared in a canonical form, so member order and
+// formatting do not matter; numbers are compared as written. Neither token is
+// verified: this is a utility for cache invalidation and diagnostics, not a
+// security check. Malformed tokens yield ErrInvalidToken.
+func SameClaims(a, b string) (bool, error) {
+	canonicalA, err := canonicalPayload(a)
+
+	if err != nil {
+		return false, err
+	}
+
+	canonicalB, err := canonicalPayload(b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(canonicalA, canonicalB), nil
+}
+
+// canonicalPayload decodes the payload of jws and re-encodes it with sorted
+// object members, no insignificant whitespace, and numbers kept verbatim.
+func canonicalPayload(jws string) ([]byte, error) {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeJWTBase64(b64vals.payload)
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims any
+
+	dec := json.NewDecoder(bytes.NewReader(decoded))
+	dec.UseNumber()
+
+	if err := dec.Decode(&claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrInvalidToken
+	}
+
+	return json.Marshal(claims)
+}
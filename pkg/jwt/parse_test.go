@@ -0,0 +1,305 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDecodeHeader verifies the header is decoded without a key
+func TestDecodeHeader(t *testing.T) {
+	token, _ := Marshal(Header{Alg: HS384}, Claims{Subject: "user123"}, []byte("secret"))
+
+	header, err := DecodeHeader(token)
+
+	if err != nil {
+		t.Fatalf("DecodeHeader() error = %v", err)
+	}
+
+	if header.Alg != HS384 || header.Typ != JWT {
+		t.Errorf("DecodeHeader() = %+v", header)
+	}
+
+	if _, err := DecodeHeader("not-a-token"); err != ErrInvalidToken {
+		t.Errorf("DecodeHeader() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+// TestSigningInputFromToken verifies the signed bytes are returned verbatim
+func TestSigningInputFromToken(t *testing.T) {
+	secret := []byte("test-secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	got, err := SigningInputFromToken(token)
+
+	if err != nil {
+		t.Fatalf("SigningInputFromToken() error = %v", err)
+	}
+
+	if want := token[:strings.LastIndex(token, ".")]; got != want {
+		t.Errorf("SigningInputFromToken() = %q, want %q", got, want)
+	}
+
+	if signature, _ := ComputeSignature(got, HS256, secret); !strings.HasSuffix(token, "."+signature) {
+		t.Error("signing input does not reproduce the token signature")
+	}
+
+	for _, invalid := range []string{"a.b", ".payload.sig", "header..sig", "head=r.payload.sig", "a.b.c.d"} {
+		if _, err := SigningInputFromToken(invalid); err != ErrInvalidToken {
+			t.Errorf("SigningInputFromToken(%q) error = %v, want %v", invalid, err, ErrInvalidToken)
+		}
+	}
+}
+
+// TestParseUnverified verifies claims are decoded without signature or claims validation
+func TestParseUnverified(t *testing.T) {
+	claims := Claims{
+		Issuer:    "issuer",
+		Subject:   "user123",
+		ExpiresAt: time.Now().Add(-1 * time.Hour).Unix(),
+	}
+
+	token, _ := Marshal(Header{Alg: HS256}, claims, []byte("unknown-secret"))
+
+	var decoded Claims
+
+	header, err := ParseUnverified(token, &decoded)
+
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+
+	if header.Alg != HS256 {
+		t.Errorf("Alg = %v, want %v", header.Alg, HS256)
+	}
+
+	if decoded.Issuer != claims.Issuer || decoded.Subject != claims.Subject {
+		t.Errorf("decoded = %+v, want %+v", decoded, claims)
+	}
+
+	if !header.Unverified() {
+		t.Error("Unverified() = false, want true")
+	}
+
+	malformed := []string{
+		"a.b",
+		token + ".extra",
+		encodeJWTBase64([]byte(`{"alg":"HS256"`)) + "." + encodeJWTBase64([]byte(`{}`)) + ".",
+		encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "." + encodeJWTBase64([]byte(`{"sub":`)) + ".",
+		encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + ".!!." + "c2ln",
+		encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "." + encodeJWTBase64([]byte(`null`)) + ".",
+		encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "." + encodeJWTBase64([]byte(`["sub"]`)) + ".",
+	}
+
+	for _, jws := range malformed {
+		if _, err := ParseUnverified(jws, &Claims{}); err != ErrInvalidToken {
+			t.Errorf("ParseUnverified(%q) error = %v, want %v", jws, err, ErrInvalidToken)
+		}
+	}
+
+	t.Run("verified headers are not marked", func(t *testing.T) {
+		info, err := Parse(token, []byte("unknown-secret"), WithNow(time.Now().Add(-2*time.Hour)))
+
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if info.Header.Unverified() {
+			t.Error("Unverified() = true for a verified token")
+		}
+	})
+}
+
+// TestParse verifies Parse reports the header, claims, and raw segments of a token
+func TestParse(t *testing.T) {
+	key := []byte("secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, key)
+
+	info, err := Parse(token, key)
+
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if !info.Valid {
+		t.Error("Valid = false, want true")
+	}
+
+	if info.Header.Alg != HS256 {
+		t.Errorf("Alg = %v, want %v", info.Header.Alg, HS256)
+	}
+
+	if got := info.RawHeader + "." + info.RawPayload + "." + info.RawSignature; got != token {
+		t.Errorf("raw segments = %q, want %q", got, token)
+	}
+
+	if info.SigningInput != info.RawHeader+"."+info.RawPayload {
+		t.Errorf("SigningInput = %q", info.SigningInput)
+	}
+
+	claims, ok := info.Claims.(*map[string]any)
+
+	if !ok || (*claims)["sub"] != "user123" {
+		t.Errorf("Claims = %v", info.Claims)
+	}
+}
+
+// TestParseClaimsTarget verifies WithClaimsTarget decodes the claims into the given value
+func TestParseClaimsTarget(t *testing.T) {
+	key := []byte("secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, key)
+
+	var claims Claims
+
+	info, err := Parse(token, key, WithClaimsTarget(&claims))
+
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if info.Claims != &claims || claims.Subject != "user123" {
+		t.Errorf("Claims = %v, want %v", info.Claims, &claims)
+	}
+}
+
+// TestParseInvalid verifies a well-formed but invalid token returns both the info and the error
+func TestParseInvalid(t *testing.T) {
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, []byte("secret"))
+
+	info, err := Parse(token, []byte("wrong"))
+
+	if err != ErrSignatureMismatch {
+		t.Errorf("Parse() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	if info == nil || info.Valid || info.Header.Alg != HS256 {
+		t.Errorf("info = %+v", info)
+	}
+
+	if info, err := Parse("a.b", []byte("secret")); info != nil || err != ErrInvalidToken {
+		t.Errorf("Parse() = %v, %v, want nil, %v", info, err, ErrInvalidToken)
+	}
+}
+
+// TestValidate verifies structural checks run without a key
+func TestValidate(t *testing.T) {
+	token, _ := Marshal(Header{Alg: HS512}, Claims{Subject: "user123"}, []byte("secret"))
+	parts := strings.Split(token, ".")
+
+	header := func(json string) string {
+		return encodeJWTBase64([]byte(json))
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:    "well-formed token",
+			token:   token,
+			wantErr: nil,
+		},
+		{
+			name:    "wrong signature still well-formed",
+			token:   parts[0] + "." + parts[1] + "." + encodeJWTBase64(make([]byte, 64)),
+			wantErr: nil,
+		},
+		{
+			name:    "two segments",
+			token:   parts[0] + "." + parts[1],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "invalid base64 payload",
+			token:   parts[0] + ".not*base64." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "header not an object",
+			token:   header(`["HS512"]`) + "." + parts[1] + "." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "null header",
+			token:   header(`null`) + "." + parts[1] + "." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "payload not an object",
+			token:   parts[0] + "." + header(`"user123"`) + "." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "empty payload",
+			token:   parts[0] + ".." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "unknown algorithm",
+			token:   header(`{"alg":"none","typ":"JWT"}`) + "." + parts[1] + ".",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "signature of the wrong length",
+			token:   parts[0] + "." + parts[1] + "." + parts[2][:43],
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.token); err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSameClaims verifies claims are compared canonically, ignoring signatures
+func TestSameClaims(t *testing.T) {
+	sign := func(payload string, secret string) string {
+		header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		encoded := encodeJWTBase64([]byte(payload))
+		signature, _ := ComputeSignature(header+"."+encoded, HS256, []byte(secret))
+		return header + "." + encoded + "." + signature
+	}
+
+	original := sign(`{"sub":"user123","roles":["admin"],"ctx":{"a":1,"b":2}}`, "old-key")
+
+	tests := []struct {
+		name  string
+		other string
+		want  bool
+	}{
+		{name: "identical token", other: original, want: true},
+		{name: "rotated key", other: sign(`{"sub":"user123","roles":["admin"],"ctx":{"a":1,"b":2}}`, "new-key"), want: true},
+		{name: "reordered members", other: sign(`{ "ctx": {"b":2, "a":1}, "roles": ["admin"], "sub": "user123" }`, "new-key"), want: true},
+		{name: "different value", other: sign(`{"sub":"user456","roles":["admin"],"ctx":{"a":1,"b":2}}`, "old-key"), want: false},
+		{name: "extra claim", other: sign(`{"sub":"user123","roles":["admin"],"ctx":{"b":2,"a":1},"extra":true}`, "old-key"), want: false},
+		{name: "different header", other: strings.Replace(original, original[:strings.Index(original, ".")], encodeJWTBase64([]byte(`{"alg":"HS512"}`)), 1), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SameClaims(original, tt.other)
+
+			if err != nil {
+				t.Fatalf("SameClaims() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("SameClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"a.b", "header.!!!.sig", sign(`{"sub":`, "key"), sign(`{} {}`, "key")} {
+		if _, err := SameClaims(original, invalid); err != ErrInvalidToken {
+			t.Errorf("SameClaims(%q) error = %v, want %v", invalid, err, ErrInvalidToken)
+		}
+	}
+}
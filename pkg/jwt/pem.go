@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// ParsePEMPublicKey parses the first PEM block of data as a public key. It
+// accepts PKIX "PUBLIC KEY", PKCS #1 "RSA PUBLIC KEY", and "CERTIFICATE"
+// blocks, the latter yielding the certificate's public key.
+func ParsePEMPublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return cert.PublicKey, nil
+	}
+
+	return nil, unsupportedPEMTypeError{typ: block.Type}
+}
+
+// ParsePEMPrivateKey parses the first PEM block of data as a private key. It
+// accepts PKCS #8 "PRIVATE KEY", PKCS #1 "RSA PRIVATE KEY", and SEC 1
+// "EC PRIVATE KEY" blocks. Encrypted PEM blocks are not supported.
+func ParsePEMPrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	return nil, unsupportedPEMTypeError{typ: block.Type}
+}
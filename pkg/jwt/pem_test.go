@@ -0,0 +1,149 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// encodePEM wraps DER bytes in a PEM block of the given type
+func encodePEM(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+// equalKeys compares keys with their Equal methods; DeepEqual is unreliable
+// for RSA private keys, whose precomputed values are not always populated
+func equalKeys(got, want any) bool {
+	switch key := got.(type) {
+	case interface{ Equal(crypto.PrivateKey) bool }:
+		return key.Equal(want)
+	case interface{ Equal(crypto.PublicKey) bool }:
+		return key.Equal(want)
+	}
+
+	return false
+}
+
+// TestParsePEMPrivateKey verifies PKCS #1, PKCS #8, and SEC 1 private keys parse
+func TestParsePEMPrivateKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	pkcs8RSA, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	pkcs8EC, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	pkcs8Ed, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+
+	tests := []struct {
+		name string
+		pem  []byte
+		want any
+	}{
+		{name: "PKCS #1 RSA", pem: encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), want: rsaKey},
+		{name: "PKCS #8 RSA", pem: encodePEM("PRIVATE KEY", pkcs8RSA), want: rsaKey},
+		{name: "PKCS #8 EC", pem: encodePEM("PRIVATE KEY", pkcs8EC), want: ecKey},
+		{name: "PKCS #8 Ed25519", pem: encodePEM("PRIVATE KEY", pkcs8Ed), want: edKey},
+		{name: "SEC 1 EC", pem: encodePEM("EC PRIVATE KEY", sec1), want: ecKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePEMPrivateKey(tt.pem)
+
+			if err != nil {
+				t.Fatalf("ParsePEMPrivateKey() error = %v", err)
+			}
+
+			if !equalKeys(got, tt.want) {
+				t.Errorf("ParsePEMPrivateKey() returned a different key")
+			}
+		})
+	}
+
+	t.Run("unsupported block type", func(t *testing.T) {
+		_, err := ParsePEMPrivateKey(encodePEM("PUBLIC KEY", []byte("x")))
+
+		if err != (unsupportedPEMTypeError{typ: "PUBLIC KEY"}) {
+			t.Errorf("ParsePEMPrivateKey() error = %v, want unsupported PEM type", err)
+		}
+	})
+
+	t.Run("not PEM", func(t *testing.T) {
+		if _, err := ParsePEMPrivateKey([]byte("secret")); err != ErrInvalidPEM {
+			t.Errorf("ParsePEMPrivateKey() error = %v, want %v", err, ErrInvalidPEM)
+		}
+	})
+
+	t.Run("corrupt key", func(t *testing.T) {
+		if _, err := ParsePEMPrivateKey(encodePEM("PRIVATE KEY", []byte("garbage"))); err == nil {
+			t.Error("ParsePEMPrivateKey() error = nil, want an error")
+		}
+	})
+}
+
+// TestParsePEMPublicKey verifies PKIX, PKCS #1, and certificate public keys parse
+func TestParsePEMPublicKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	pkixRSA, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pkixEC, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	cert, _ := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+
+	tests := []struct {
+		name string
+		pem  []byte
+		want any
+	}{
+		{name: "PKIX RSA", pem: encodePEM("PUBLIC KEY", pkixRSA), want: &rsaKey.PublicKey},
+		{name: "PKIX EC", pem: encodePEM("PUBLIC KEY", pkixEC), want: &ecKey.PublicKey},
+		{name: "PKCS #1 RSA", pem: encodePEM("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)), want: &rsaKey.PublicKey},
+		{name: "certificate", pem: encodePEM("CERTIFICATE", cert), want: &ecKey.PublicKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePEMPublicKey(tt.pem)
+
+			if err != nil {
+				t.Fatalf("ParsePEMPublicKey() error = %v", err)
+			}
+
+			if !equalKeys(got, tt.want) {
+				t.Errorf("ParsePEMPublicKey() returned a different key")
+			}
+		})
+	}
+
+	t.Run("unsupported block type", func(t *testing.T) {
+		_, err := ParsePEMPublicKey(encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)))
+
+		if err != (unsupportedPEMTypeError{typ: "RSA PRIVATE KEY"}) {
+			t.Errorf("ParsePEMPublicKey() error = %v, want unsupported PEM type", err)
+		}
+	})
+
+	t.Run("not PEM", func(t *testing.T) {
+		if _, err := ParsePEMPublicKey(nil); err != ErrInvalidPEM {
+			t.Errorf("ParsePEMPublicKey() error = %v, want %v", err, ErrInvalidPEM)
+		}
+	})
+}
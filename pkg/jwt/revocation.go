@@ -0,0 +1,30 @@
+package jwt
+
+// RevocationStore reports whether a token has been revoked. It receives the
+// registered claims of a token whose signature has been verified, so
+// implementations can revoke by 'jti', by 'sub', by an 'iat' cutoff, or by any
+// combination, backed by whatever storage suits them.
+type RevocationStore interface {
+	IsRevoked(claims Claims) (bool, error)
+}
+
+// WithRevocationStore makes Unmarshal consult store after the signature is
+// verified and reject revoked tokens with ErrTokenRevoked. An error from the
+// store is returned verbatim, so store outages fail closed.
+func WithRevocationStore(store RevocationStore) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			revoked, err := store.IsRevoked(*c)
+
+			if err != nil {
+				return err
+			}
+
+			if revoked {
+				return ErrTokenRevoked
+			}
+
+			return nil
+		}))
+	}
+}
@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+// revocationFunc adapts a function into a RevocationStore
+type revocationFunc func(claims Claims) (bool, error)
+
+func (f revocationFunc) IsRevoked(claims Claims) (bool, error) {
+	return f(claims)
+}
+
+// TestWithRevocationStore verifies Unmarshal rejects tokens the store reports as revoked
+func TestWithRevocationStore(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	errStore := errors.New("store unavailable")
+
+	revokedIDs := map[string]bool{"revoked-id": true}
+
+	byID := revocationFunc(func(c Claims) (bool, error) {
+		return revokedIDs[c.ID], nil
+	})
+
+	bySubjectCutoff := revocationFunc(func(c Claims) (bool, error) {
+		return c.Subject == "user123" && c.IssuedAt < 1000, nil
+	})
+
+	failing := revocationFunc(func(Claims) (bool, error) {
+		return false, errStore
+	})
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		store   RevocationStore
+		wantErr error
+	}{
+		{
+			name:    "not revoked",
+			claims:  Claims{ID: "live-id"},
+			store:   byID,
+			wantErr: nil,
+		},
+		{
+			name:    "revoked by jti",
+			claims:  Claims{ID: "revoked-id"},
+			store:   byID,
+			wantErr: ErrTokenRevoked,
+		},
+		{
+			name:    "revoked by issue-time cutoff",
+			claims:  Claims{Subject: "user123", IssuedAt: 500},
+			store:   bySubjectCutoff,
+			wantErr: ErrTokenRevoked,
+		},
+		{
+			name:    "issued after cutoff",
+			claims:  Claims{Subject: "user123", IssuedAt: 1500},
+			store:   bySubjectCutoff,
+			wantErr: nil,
+		},
+		{
+			name:    "store error",
+			claims:  Claims{ID: "live-id"},
+			store:   failing,
+			wantErr: errStore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &map[string]any{}, secret, WithRevocationStore(tt.store))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("not consulted before the signature is verified", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{ID: "live-id"}, secret)
+
+		called := false
+
+		store := revocationFunc(func(Claims) (bool, error) {
+			called = true
+			return false, nil
+		})
+
+		if err := Unmarshal(token, &Claims{}, []byte("wrong"), WithRevocationStore(store)); err != ErrSignatureMismatch {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+		}
+
+		if called {
+			t.Error("store consulted for a token with an invalid signature")
+		}
+	})
+}
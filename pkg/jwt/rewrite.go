@@ -0,0 +1,249 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Rewrite verifies and validates a token like Unmarshal, passes its claims to
+// mutate, and signs the result with the same header and key. The key is any
+// key Marshal takes; an asymmetric private key verifies the token with its
+// public key. Claims mutate does not touch, including unknown ones, are
+// preserved byte for byte and keep their original order, so the payload is
+// unchanged when mutate changes nothing; new claims are appended in sorted
+// order. Numbers are passed as json.Number so that they round-trip exactly.
+//
+// With WithAutoIssuedAt, 'iat' is set to now after mutate runs when it is
+// missing; with WithRefreshedIssuedAt it is reset to now in any case. With
+// WithEncryptedClaims, mutate sees the decrypted claims and the named ones are
+// encrypted again in the rewritten token.
+func Rewrite(jws string, key any, mutate func(claims map[string]any), opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	// Decoding into Claims validates the time claims, which a map would not
+	t := &token{
+		payload: payload{claims: &Claims{}},
+	}
+
+	if err := t.verify(jws, verificationKey(key), o); err != nil {
+		return "", err
+	}
+
+	members, err := decodeMembers(t.payload.raw)
+
+	if err != nil {
+		return "", err
+	}
+
+	claims := make(map[string]any, len(members))
+
+	for _, m := range members {
+		if claims[m.name], err = decodeNumbers(m.value); err != nil {
+			return "", err
+		}
+	}
+
+	mutate(claims)
+
+	if _, ok := claims["iat"]; o.refreshIat || (o.autoIssuedAt && !ok) {
+		claims["iat"] = o.numericDate(o.now())
+	}
+
+	rewritten, err := encodeMembers(members, claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	// The verified payload was decrypted, so the named claims are sealed again
+	if o.claimCipher != nil {
+		if rewritten, err = o.claimCipher.encrypt(rewritten); err != nil {
+			return "", err
+		}
+	}
+
+	r := &token{
+		header:         t.header,
+		payload:        payload{claims: rewritten},
+		signingContext: o.signingContext,
+	}
+
+	return r.marshal(key)
+}
+
+// WithRefreshedIssuedAt makes Rewrite reset the 'iat' claim to the current
+// time, replacing any value the token carries.
+func WithRefreshedIssuedAt() Option {
+	return func(o *options) {
+		o.refreshIat = true
+	}
+}
+
+// verificationKey returns the public key of an asymmetric private key, and
+// any other key unchanged.
+func verificationKey(key any) any {
+	if _, ok := key.([]byte); ok {
+		return key
+	}
+
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+
+	return key
+}
+
+// rawMember is a member of a JSON object, in document order.
+type rawMember struct {
+	name  string
+	value json.RawMessage
+}
+
+// decodeMembers returns the members of a JSON object in document order.
+func decodeMembers(data []byte) ([]rawMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, ErrMalformedClaims
+	}
+
+	var members []rawMember
+
+	for dec.More() {
+		tok, err := dec.Token()
+
+		if err != nil {
+			return nil, ErrMalformedClaims
+		}
+
+		m := rawMember{name: tok.(string)}
+
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, ErrMalformedClaims
+		}
+
+		members = append(members, m)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, ErrMalformedClaims
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrMalformedClaims
+	}
+
+	return members, nil
+}
+
+// encodeMembers encodes claims as a JSON object laid out like members: claims
+// still holding their decoded value keep their raw encoding and position,
+// removed ones are dropped, and new ones follow in sorted order.
+func encodeMembers(members []rawMember, claims map[string]any) (json.RawMessage, error) {
+	var encoded []rawMember
+
+	seen := make(map[string]bool, len(members))
+
+	for _, m := range members {
+		value, ok := claims[m.name]
+
+		if !ok || seen[m.name] {
+			continue
+		}
+
+		seen[m.name] = true
+
+		raw, err := encodeUnlessUnchanged(m.value, value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(encoded, rawMember{name: m.name, value: raw})
+	}
+
+	var added []string
+
+	for name := range claims {
+		if !seen[name] {
+			added = append(added, name)
+		}
+	}
+
+	sort.Strings(added)
+
+	for _, name := range added {
+		raw, err := encodeValue(claims[name])
+
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(encoded, rawMember{name: name, value: raw})
+	}
+
+	return joinMembers(encoded)
+}
+
+// joinMembers encodes members as a JSON object, in order.
+func joinMembers(members []rawMember) (json.RawMessage, error) {
+	buf := []byte{'{'}
+
+	for i, m := range members {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		name, err := encodeValue(m.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf = append(append(append(buf, name...), ':'), m.value...)
+	}
+
+	return append(buf, '}'), nil
+}
+
+// encodeUnlessUnchanged returns raw when value is still what raw decodes to,
+// and the encoding of value otherwise.
+func encodeUnlessUnchanged(raw json.RawMessage, value any) (json.RawMessage, error) {
+	if original, err := decodeNumbers(raw); err == nil && reflect.DeepEqual(original, value) {
+		return raw, nil
+	}
+
+	return encodeValue(value)
+}
+
+// encodeValue encodes a claim value like Marshal encodes claims.
+func encodeValue(value any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// decodeNumbers decodes a JSON value with numbers as json.Number.
+func decodeNumbers(raw json.RawMessage) (any, error) {
+	var value any
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRewrite verifies claims are edited and re-signed while others are preserved
+func TestRewrite(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	original := map[string]any{
+		"sub":   "user123",
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Add(-time.Hour).Unix(),
+		"big":   int64(1<<62 + 1),
+		"roles": []string{"admin"},
+	}
+
+	token, _ := Marshal(Header{Alg: HS384, Kid: "k1"}, original, secret)
+
+	rewritten, err := Rewrite(token, secret, func(claims map[string]any) {
+		claims["sub"] = "pseudonym-1"
+	}, WithNow(now))
+
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+
+	var decoded struct {
+		Subject  string      `json:"sub"`
+		IssuedAt int64       `json:"iat"`
+		Big      json.Number `json:"big"`
+		Roles    []string    `json:"roles"`
+	}
+
+	if err := Unmarshal(rewritten, &decoded, secret, WithNow(now)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.Subject != "pseudonym-1" {
+		t.Errorf("sub = %q, want %q", decoded.Subject, "pseudonym-1")
+	}
+
+	if decoded.IssuedAt != now.Add(-time.Hour).Unix() {
+		t.Errorf("iat = %v, want it unchanged", decoded.IssuedAt)
+	}
+
+	if decoded.Big.String() != "4611686018427387905" {
+		t.Errorf("big = %v, want it preserved exactly", decoded.Big)
+	}
+
+	if len(decoded.Roles) != 1 || decoded.Roles[0] != "admin" {
+		t.Errorf("roles = %v, want [admin]", decoded.Roles)
+	}
+
+	header, _ := DecodeHeader(rewritten)
+
+	if header.Alg != HS384 || header.Kid != "k1" {
+		t.Errorf("header = %v, want the original header", header)
+	}
+
+	t.Run("refreshes iat", func(t *testing.T) {
+		later := now.Add(10 * time.Minute)
+
+		rewritten, err := Rewrite(token, secret, func(map[string]any) {}, WithNow(later), WithRefreshedIssuedAt())
+
+		if err != nil {
+			t.Fatalf("Rewrite() error = %v", err)
+		}
+
+		var claims Claims
+
+		if err := Unmarshal(rewritten, &claims, secret, WithNow(later)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if claims.IssuedAt != later.Unix() {
+			t.Errorf("iat = %v, want %v", claims.IssuedAt, later.Unix())
+		}
+	})
+
+	t.Run("WithAutoIssuedAt only sets a missing iat", func(t *testing.T) {
+		later := now.Add(10 * time.Minute)
+
+		for _, tt := range []struct {
+			name   string
+			mutate func(map[string]any)
+			want   int64
+		}{
+			{name: "kept", mutate: func(map[string]any) {}, want: now.Add(-time.Hour).Unix()},
+			{name: "set", mutate: func(claims map[string]any) { delete(claims, "iat") }, want: later.Unix()},
+		} {
+			rewritten, err := Rewrite(token, secret, tt.mutate, WithNow(later), WithAutoIssuedAt())
+
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+
+			var claims Claims
+
+			_ = Unmarshal(rewritten, &claims, secret, WithNow(later))
+
+			if claims.IssuedAt != tt.want {
+				t.Errorf("%s: iat = %v, want %v", tt.name, claims.IssuedAt, tt.want)
+			}
+		}
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		called := false
+		mutate := func(map[string]any) { called = true }
+
+		if _, err := Rewrite(token, []byte("wrong"), mutate, WithNow(now)); err != ErrSignatureMismatch {
+			t.Errorf("Rewrite() error = %v, want %v", err, ErrSignatureMismatch)
+		}
+
+		if _, err := Rewrite(token, secret, mutate, WithNow(now.Add(2*time.Hour))); err != ErrTokenExpired {
+			t.Errorf("Rewrite() error = %v, want %v", err, ErrTokenExpired)
+		}
+
+		if called {
+			t.Error("mutate called for an invalid token")
+		}
+	})
+	t.Run("keeps member order and encoding", func(t *testing.T) {
+		payload := `{"z":1,"sub":"user123","a":{"y":1.50,"x":2},"exp":` + strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + `}`
+		header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		encoded := encodeJWTBase64([]byte(payload))
+		signature, _ := ComputeSignature(header+"."+encoded, HS256, secret)
+		original := header + "." + encoded + "." + signature
+
+		unchanged, err := Rewrite(original, secret, func(map[string]any) {}, WithNow(now))
+
+		if err != nil {
+			t.Fatalf("Rewrite() error = %v", err)
+		}
+
+		if unchanged != original {
+			t.Errorf("Rewrite() = %q, want the original token %q", unchanged, original)
+		}
+
+		rewritten, _ := Rewrite(original, secret, func(claims map[string]any) {
+			claims["sub"] = "pseudonym-1"
+			claims["new"] = true
+			delete(claims, "z")
+		}, WithNow(now))
+
+		b64vals := b64values{}
+		_ = b64vals.unmarshal(rewritten)
+		got, _ := decodeJWTBase64(b64vals.payload)
+
+		want := `{"sub":"pseudonym-1","a":{"y":1.50,"x":2},"exp":` + strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + `,"new":true}`
+
+		if string(got) != want {
+			t.Errorf("payload = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("asymmetric and key set keys", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		keys := SymmetricKeySet{"k1": secret}
+
+		for _, tt := range []struct {
+			name        string
+			header      Header
+			key, verify any
+		}{
+			{name: "RS256", header: Header{Alg: RS256}, key: rsaKey, verify: &rsaKey.PublicKey},
+			{name: "key set", header: Header{Alg: HS256, Kid: "k1"}, key: keys, verify: keys},
+		} {
+			token, _ := Marshal(tt.header, Claims{Subject: "user123"}, tt.key)
+
+			rewritten, err := Rewrite(token, tt.key, func(claims map[string]any) { claims["sub"] = "pseudonym-1" })
+
+			if err != nil {
+				t.Fatalf("%s: Rewrite() error = %v", tt.name, err)
+			}
+
+			var claims Claims
+
+			if err := Unmarshal(rewritten, &claims, tt.verify); err != nil || claims.Subject != "pseudonym-1" {
+				t.Errorf("%s: Unmarshal() = %+v, %v, want the rewritten subject", tt.name, claims, err)
+			}
+		}
+	})
+}
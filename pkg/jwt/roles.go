@@ -0,0 +1,59 @@
+package jwt
+
+import "encoding/json"
+
+// Roles represents a 'roles' claim listing authorization roles. Like Audience
+// it decodes from either a single string or an array of strings, but it is
+// always encoded as an array.
+type Roles []string
+
+// MarshalJSON encodes the roles as an array, empty when there are none.
+func (r Roles) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]string(r))
+}
+
+// UnmarshalJSON decodes either a string or an array of strings with the same
+// rules as Audience: any other JSON value fails with ErrMalformedClaims and a
+// null leaves the roles unchanged.
+func (r *Roles) UnmarshalJSON(data []byte) error {
+	return (*Audience)(r).UnmarshalJSON(data)
+}
+
+// Has reports whether role is one of the roles. Roles are compared exactly.
+func (r Roles) Has(role string) bool {
+	for _, candidate := range r {
+		if candidate == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithRequiredRole makes Unmarshal reject tokens whose 'roles' claim, a string
+// or an array of strings, is missing, malformed, or does not list role with
+// ErrMissingRole. Several WithRequiredRole options require every role. Without
+// this option the claim is ignored.
+func WithRequiredRole(role string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, func(t *token) error {
+			fields, err := t.payload.rawClaims()
+
+			if err != nil {
+				return err
+			}
+
+			var roles Roles
+
+			if raw, ok := fields["roles"]; !ok || json.Unmarshal(raw, &roles) != nil || !roles.Has(role) {
+				return ErrMissingRole
+			}
+
+			return nil
+		})
+	}
+}
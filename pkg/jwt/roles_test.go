@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestRolesJSON verifies roles decode from both forms and encode as an array
+func TestRolesJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Roles
+		wantErr bool
+	}{
+		{name: "string", input: `"admin"`, want: Roles{"admin"}},
+		{name: "array", input: `["admin","editor"]`, want: Roles{"admin", "editor"}},
+		{name: "null", input: `null`, want: nil},
+		{name: "number", input: `1`, wantErr: true},
+		{name: "array with a number", input: `["admin",1]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Roles
+
+			err := json.Unmarshal([]byte(tt.input), &got)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("json.Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	for _, roles := range []Roles{nil, {"admin"}, {"admin", "editor"}} {
+		got, _ := json.Marshal(roles)
+
+		var decoded []string
+
+		if err := json.Unmarshal(got, &decoded); err != nil {
+			t.Errorf("json.Marshal(%#v) = %s, want an array", roles, got)
+		}
+	}
+}
+
+// TestWithRequiredRole verifies role membership is enforced when configured
+func TestWithRequiredRole(t *testing.T) {
+	secret := []byte("test-secret")
+
+	type roleClaims struct {
+		Claims
+		Roles any `json:"roles,omitempty"`
+	}
+
+	tests := []struct {
+		name    string
+		roles   any
+		opts    []Option
+		wantErr error
+	}{
+		{name: "listed in array", roles: []string{"admin", "editor"}, opts: []Option{WithRequiredRole("admin")}},
+		{name: "scalar string", roles: "admin", opts: []Option{WithRequiredRole("admin")}},
+		{name: "every required role", roles: []string{"admin", "editor"}, opts: []Option{WithRequiredRole("admin"), WithRequiredRole("editor")}},
+		{name: "one role missing", roles: []string{"admin"}, opts: []Option{WithRequiredRole("admin"), WithRequiredRole("editor")}, wantErr: ErrMissingRole},
+		{name: "not listed", roles: []string{"viewer"}, opts: []Option{WithRequiredRole("admin")}, wantErr: ErrMissingRole},
+		{name: "case-sensitive", roles: "Admin", opts: []Option{WithRequiredRole("admin")}, wantErr: ErrMissingRole},
+		{name: "claim absent", opts: []Option{WithRequiredRole("admin")}, wantErr: ErrMissingRole},
+		{name: "malformed claim", roles: 42, opts: []Option{WithRequiredRole("admin")}, wantErr: ErrMissingRole},
+		{name: "not validated by default", roles: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256}, roleClaims{Roles: tt.roles}, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("decodes into Roles", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, roleClaims{Roles: "admin"}, secret)
+
+		var decoded struct {
+			Claims
+			Roles Roles `json:"roles"`
+		}
+
+		if err := Unmarshal(token, &decoded, secret, WithRequiredRole("admin")); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if !decoded.Roles.Has("admin") {
+			t.Errorf("Roles = %v, want admin", decoded.Roles)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import "sync"
+
+// RotatingSecret holds the HMAC secret signing new tokens and the secret it
+// replaced, which is still accepted so that tokens issued before a rotation
+// keep verifying until they expire. It is safe for concurrent use.
+type RotatingSecret struct {
+	// OnStaleKey, when set, is called after a token verifies against a secret
+	// other than the active one, with the number of rotations since that
+	// secret was active, i.e. 1 for the previous secret. It signals tokens due
+	// for reissuing and cannot change the result. Set it before concurrent use.
+	OnStaleKey func(index int)
+
+	mu       sync.RWMutex
+	active   []byte
+	previous []byte
+}
+
+// NewRotatingSecret returns a RotatingSecret signing and verifying with active.
+func NewRotatingSecret(active []byte) *RotatingSecret {
+	return &RotatingSecret{active: active}
+}
+
+// Advance makes secret the active secret and demotes the current one to
+// accepted for verification only. The secret accepted before is dropped, so
+// the interval between rotations must exceed the lifetime of the tokens.
+func (r *RotatingSecret) Advance(secret []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.previous = r.active
+	r.active = secret
+}
+
+// Marshal signs the token with the active secret like Marshal.
+func (r *RotatingSecret) Marshal(header Header, claims any, opts ...Option) (string, error) {
+	active, _ := r.secrets()
+
+	return Marshal(header, claims, active, opts...)
+}
+
+// Unmarshal decodes and validates a JWT like Unmarshal, verifying its
+// signature with the active secret and then with the previous one within a
+// single verification, so WithOnVerify reports one event per call.
+// ErrSignatureMismatch is returned when neither verifies it.
+func (r *RotatingSecret) Unmarshal(jws string, claims any, opts ...Option) error {
+	t, err := verifyInto(jws, claims, r, newOptions(opts))
+
+	if err != nil {
+		return err
+	}
+
+	if t.keyIndex > 0 && r.OnStaleKey != nil {
+		r.OnStaleKey(t.keyIndex)
+	}
+
+	return nil
+}
+
+// resolveKey returns the secrets accepted for verification, the active one
+// first.
+func (r *RotatingSecret) resolveKey(h Header) (any, error) {
+	active, previous := r.secrets()
+
+	if previous == nil {
+		return candidateKeys{active}, nil
+	}
+
+	return candidateKeys{active, previous}, nil
+}
+
+func (r *RotatingSecret) secrets() (active, previous []byte) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.active, r.previous
+}
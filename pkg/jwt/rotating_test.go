@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestRotatingSecret verifies tokens signed before a rotation verify until the next one
+func TestRotatingSecret(t *testing.T) {
+	secrets := NewRotatingSecret([]byte("secret-1"))
+
+	first, err := secrets.Marshal(Header{Alg: HS256}, Claims{Subject: "user123"})
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	secrets.Advance([]byte("secret-2"))
+
+	second, _ := secrets.Marshal(Header{Alg: HS256}, Claims{Subject: "user456"})
+
+	if err := Unmarshal(second, &Claims{}, []byte("secret-2")); err != nil {
+		t.Errorf("Unmarshal() with the active secret error = %v", err)
+	}
+
+	for _, token := range []string{first, second} {
+		var decoded Claims
+
+		if err := secrets.Unmarshal(token, &decoded); err != nil {
+			t.Errorf("RotatingSecret.Unmarshal() error = %v", err)
+		}
+	}
+
+	secrets.Advance([]byte("secret-3"))
+
+	if err := secrets.Unmarshal(second, &Claims{}); err != nil {
+		t.Errorf("RotatingSecret.Unmarshal() with the previous secret error = %v", err)
+	}
+
+	if err := secrets.Unmarshal(first, &Claims{}); err != ErrSignatureMismatch {
+		t.Errorf("RotatingSecret.Unmarshal() with a dropped secret error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+// TestRotatingSecretOnStaleKey verifies the callback only fires for tokens verified by the previous secret
+func TestRotatingSecretOnStaleKey(t *testing.T) {
+	var calls []int
+
+	secrets := NewRotatingSecret([]byte("secret-1"))
+	secrets.OnStaleKey = func(index int) {
+		calls = append(calls, index)
+	}
+
+	stale, _ := secrets.Marshal(Header{Alg: HS256}, Claims{})
+	expired, _ := secrets.Marshal(Header{Alg: HS256}, Claims{ExpiresAt: 1})
+
+	secrets.Advance([]byte("secret-2"))
+
+	active, _ := secrets.Marshal(Header{Alg: HS256}, Claims{})
+
+	if err := secrets.Unmarshal(active, &Claims{}); err != nil {
+		t.Fatalf("RotatingSecret.Unmarshal() with the active secret error = %v", err)
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("OnStaleKey calls = %v after an active secret verification, want none", calls)
+	}
+
+	if err := secrets.Unmarshal(stale, &Claims{}); err != nil {
+		t.Fatalf("RotatingSecret.Unmarshal() with the previous secret error = %v", err)
+	}
+
+	if err := secrets.Unmarshal(expired, &Claims{}); err != ErrTokenExpired {
+		t.Fatalf("RotatingSecret.Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+	}
+
+	if err := secrets.Unmarshal(stale[:len(stale)-2]+"AA", &Claims{}); err != ErrSignatureMismatch {
+		t.Fatalf("RotatingSecret.Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	if len(calls) != 1 || calls[0] != 1 {
+		t.Errorf("OnStaleKey calls = %v, want [1]", calls)
+	}
+}
+
+// TestRotatingSecretSingleVerification verifies the previous secret is tried within one verification
+func TestRotatingSecretSingleVerification(t *testing.T) {
+	secrets := NewRotatingSecret([]byte("secret-1"))
+	stale, _ := secrets.Marshal(Header{Alg: HS256}, Claims{})
+
+	secrets.Advance([]byte("secret-2"))
+
+	var events []VerifyEvent
+
+	debugCalls := 0
+
+	opts := []Option{
+		WithOnVerify(func(e VerifyEvent) { events = append(events, e) }),
+		WithVerifyDebug(func(computed, provided []byte) { debugCalls++ }),
+	}
+
+	if err := secrets.Unmarshal(stale, &Claims{}, opts...); err != nil {
+		t.Fatalf("RotatingSecret.Unmarshal() error = %v", err)
+	}
+
+	if len(events) != 1 || events[0].Err != nil {
+		t.Errorf("events = %+v, want one successful event", events)
+	}
+
+	if debugCalls != 0 {
+		t.Errorf("WithVerifyDebug calls = %d, want 0", debugCalls)
+	}
+
+	events = nil
+
+	if err := secrets.Unmarshal(stale[:len(stale)-2]+"AA", &Claims{}, opts...); err != ErrSignatureMismatch {
+		t.Fatalf("RotatingSecret.Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	if len(events) != 1 || events[0].Err != ErrSignatureMismatch || debugCalls != 1 {
+		t.Errorf("events = %+v, debug calls = %d, want one failed event and one debug call", events, debugCalls)
+	}
+}
+
+// TestRotatingSecretValidation verifies validation errors are not retried with the previous secret
+func TestRotatingSecretValidation(t *testing.T) {
+	secrets := NewRotatingSecret([]byte("secret-1"))
+	secrets.Advance([]byte("secret-2"))
+
+	token, _ := secrets.Marshal(Header{Alg: HS256}, Claims{Issuer: "other"})
+
+	if err := secrets.Unmarshal(token, &Claims{}, WithIssuer("issuer")); err != ErrInvalidIssuer {
+		t.Errorf("RotatingSecret.Unmarshal() error = %v, want %v", err, ErrInvalidIssuer)
+	}
+
+	if err := NewRotatingSecret([]byte("secret-1")).Unmarshal(token, &Claims{}); err != ErrSignatureMismatch {
+		t.Errorf("RotatingSecret.Unmarshal() without a previous secret error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+// TestRotatingSecretConcurrent verifies signing and verifying race safely with rotations
+func TestRotatingSecretConcurrent(t *testing.T) {
+	secrets := NewRotatingSecret([]byte("secret-0"))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				token, err := secrets.Marshal(Header{Alg: HS256}, Claims{Subject: "user123"})
+
+				if err != nil {
+					t.Errorf("Marshal() error = %v", err)
+					return
+				}
+
+				// A rotation may drop the secret between signing and verifying
+				if err := secrets.Unmarshal(token, &Claims{}); err != nil && err != ErrSignatureMismatch {
+					t.Errorf("RotatingSecret.Unmarshal() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 50; i++ {
+		secrets.Advance([]byte{byte(i)})
+	}
+
+	wg.Wait()
+}
@@ -1,32 +1,382 @@
 package jwt
 
-// Marshal generates a JWT from the header, claims, and secret.
-func Marshal(header Header, claims any, secret []byte) (string, error) {
-	if header.Typ == "" {
+import (
+	"reflect"
+	"runtime"
+)
+
+// Marshal generates a JWT from the header, claims, and signing key. The key
+// type must match the header algorithm; HMAC algorithms take a []byte secret,
+// RSA a crypto.Signer holding an RSA key, ECDSA an *ecdsa.PrivateKey, EdDSA an
+// ed25519.PrivateKey, and "none" only UnsafeAllowNoneSignature.
+func Marshal(header Header, claims any, key any, opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	if o.omitTyp {
+		header.Typ = ""
+	} else if header.Typ == "" {
 		header.Typ = JWT
 	}
 
-	return (&token{header: header, payload: payload{claims: claims}}).marshal(secret)
+	claims = o.prepareClaims(claims)
+
+	if o.claimCipher != nil {
+		encrypted, err := o.claimCipher.encrypt(claims)
+
+		if err != nil {
+			return "", err
+		}
+
+		claims = encrypted
+	}
+
+	t := &token{
+		header:         header,
+		payload:        payload{claims: claims},
+		signingContext: o.signingContext,
+	}
+
+	return t.marshal(key)
+}
+
+// Unmarshal decodes and validates a JWT. The verification key is dispatched on
+// the header algorithm and ErrInvalidKeyType is returned when its type does not
+// match; HMAC algorithms take a []byte secret, RSA an *rsa.PublicKey, ECDSA an
+// *ecdsa.PublicKey, and EdDSA an ed25519.PublicKey. A "none" token is rejected
+// as an unsupported algorithm unless the key is UnsafeAllowNoneSignature.
+//
+// The claims are decoded into a fresh value that is only stored into claims
+// once every check has passed, so on error claims is left unchanged. On
+// success it is replaced wholesale, including fields the token does not set.
+func Unmarshal(jws string, claims any, key any, opts ...Option) error {
+	_, err := verifyInto(jws, claims, key, newOptions(opts))
+
+	return err
 }
 
-// Unmarshal decodes and validates a JWT.
-func Unmarshal(jws string, claims any, secret []byte) error {
+// verifyInto verifies jws and decodes its claims into a temporary value,
+// copying it into claims only after all validation has passed.
+func verifyInto(jws string, claims any, key any, o *options) (*token, error) {
+	target := claims
+	rv := reflect.ValueOf(claims)
+	staged := rv.Kind() == reflect.Ptr && !rv.IsNil()
+
+	if staged {
+		target = reflect.New(rv.Elem().Type()).Interface()
+	}
+
 	t := &token{
-		payload: payload{claims: claims},
+		payload: payload{claims: target},
+	}
+
+	if err := t.verify(jws, key, o); err != nil {
+		return t, err
+	}
+
+	if staged {
+		rv.Elem().Set(reflect.ValueOf(target).Elem())
 	}
 
-	if err := t.unmarshal(jws, secret); err != nil {
+	if o.onValid != nil {
+		o.onValid(claims)
+	}
+
+	return t, nil
+}
+
+// verify verifies the signature of a JWT and then validates its type and claims.
+func (t *token) verify(jws string, key any, o *options) (err error) {
+	if o.onVerify != nil {
+		defer func() {
+			o.onVerify(VerifyEvent{Alg: t.algorithm(), Duration: t.verifyDuration, Err: err})
+		}()
+	}
+
+	if err := t.unmarshal(jws, key, o); err != nil {
 		return err
 	}
 
-	if t.header.Typ != JWT {
+	if !o.allowsType(t.header.Typ) {
 		return unsupportedTypeError{typ: t.header.Typ}
 	}
 
-	if v, ok := claims.(Claimer); ok {
-		if err := v.Valid(); err != nil {
+	if err := t.validateClaims(o); err != nil {
+		return err
+	}
+
+	return o.validate(t)
+}
+
+// strictAlgorithms are the algorithms UnmarshalStrict allows: every supported
+// one except "none"
+var strictAlgorithms = []string{HS256, HS384, HS512, RS256, RS384, RS512, ES256, ES384, ES512, EdDSA}
+
+// UnmarshalStrict is Unmarshal with the recommended secure baseline for
+// high-assurance routes. On top of the signature and 'typ' checks and the
+// exp, nbf, and iat validation Unmarshal always performs, it applies
+//
+//	WithAllowedAlgorithms(HS256, HS384, HS512, RS256, RS384, RS512, ES256, ES384, ES512, EdDSA)
+//	WithRequiredClaims("exp", "iat")
+//
+// so 'none' and unknown algorithms are rejected and tokens must expire. The
+// given options are applied after these; they may add checks, and a
+// WithAllowedAlgorithms among them replaces the allowlist.
+func UnmarshalStrict(jws string, claims any, key any, opts ...Option) error {
+	strict := []Option{
+		WithAllowedAlgorithms(strictAlgorithms...),
+		WithRequiredClaims("exp", "iat"),
+	}
+
+	return Unmarshal(jws, claims, key, append(strict, opts...)...)
+}
+
+// UnmarshalMulti decodes and validates a JWT like Unmarshal, verifying it with
+// the key registered for its 'alg' header in keys, e.g. a []byte secret for
+// HS256. Only the algorithms in keys are allowed, so any other 'alg' fails
+// with ErrUnexpectedAlgorithm before the signature is verified, and a key
+// whose type does not match its algorithm yields ErrInvalidKeyType. A key may
+// itself be a SymmetricKeySet resolving by 'kid'. A WithAllowedAlgorithms
+// among opts replaces the allowlist, but algorithms without a key still fail.
+func UnmarshalMulti(jws string, claims any, keys map[string]any, opts ...Option) error {
+	algs := make([]string, 0, len(keys))
+
+	for alg := range keys {
+		algs = append(algs, alg)
+	}
+
+	allowed := []Option{WithAllowedAlgorithms(algs...)}
+
+	return Unmarshal(jws, claims, algorithmKeys(keys), append(allowed, opts...)...)
+}
+
+// UnmarshalAllowExpired decodes and validates a JWT like Unmarshal, except that
+// an expired token is not an error: the claims are still fully decoded and
+// validated and expired reports whether 'exp' has passed. Signature, 'nbf',
+// and every other failure is returned as an error. It suits flows such as
+// silent refresh that must inspect an authentic, just-expired token.
+func UnmarshalAllowExpired(jws string, claims any, key any, opts ...Option) (expired bool, err error) {
+	o := newOptions(opts)
+	o.allowExpired = true
+
+	t, err := verifyInto(jws, claims, key, o)
+
+	if err != nil {
+		return false, err
+	}
+
+	registered, err := t.payload.registeredClaims()
+
+	if err != nil {
+		return false, err
+	}
+
+	if o.zeroExpiry(t) {
+		return true, nil
+	}
+
+	return registered.ExpiresAt != 0 && o.numericDate(o.now().Add(-o.leeway)) >= registered.ExpiresAt, nil
+}
+
+// IsValid reports whether the JWT passes the same verification and validation
+// as Unmarshal with the given options. The decoded claims are discarded.
+func IsValid(jws string, key any, opts ...Option) bool {
+	var claims Claims
+
+	return Unmarshal(jws, &claims, key, opts...) == nil
+}
+
+// VerifiedAlgorithm verifies and validates jws like Unmarshal and returns the
+// algorithm that authenticated it: the 'alg' header, or the algorithm of
+// WithForcedAlgorithm. The algorithm is only returned once the signature and
+// every option, including WithAllowedAlgorithms, have passed, so unlike
+// DecodeHeader it can be trusted, for example in audit logs.
+func VerifiedAlgorithm(jws string, key any, opts ...Option) (string, error) {
+	t, err := verifyInto(jws, &Claims{}, key, newOptions(opts))
+
+	if err != nil {
+		return "", err
+	}
+
+	return t.algorithm(), nil
+}
+
+// ParseInto verifies and validates jws like Unmarshal, with any key Unmarshal
+// takes, and returns its claims decoded into a new T together with its header.
+// A pointer T, such as *Claims, is validated like the type it points to. On
+// error the zero T and an empty Header are returned, so nothing from a
+// rejected token leaks out.
+func ParseInto[T any](jws string, key any, opts ...Option) (T, Header, error) {
+	var claims T
+
+	t, err := verifyInto(jws, &claims, key, newOptions(opts))
+
+	if err != nil {
+		var zero T
+
+		return zero, Header{}, err
+	}
+
+	return claims, t.header, nil
+}
+
+// UnmarshalJWS verifies the signature of a JWS and decodes its payload into
+// claims. Unlike Unmarshal it neither checks the 'typ' header nor validates
+// the claims, so it suits plain JWS payloads that are not JWTs.
+func UnmarshalJWS(jws string, claims any, key any) error {
+	t := &token{
+		payload: payload{claims: claims},
+	}
+
+	return t.unmarshal(jws, key, newOptions(nil))
+}
+
+// claimsValidator is implemented by Claims and by any type embedding it.
+type claimsValidator interface {
+	validate(o *options) error
+}
+
+// validateClaims validates the time claims of the token. For types that are
+// or embed Claims, or tag an 'exp' field, whose value cannot tell an 'exp' of
+// 0 from an absent one, an explicit 'exp' of 0 is expired.
+func (t *token) validateClaims(o *options) error {
+	claims := indirectClaims(t.payload.claims)
+
+	if !o.allowExpired && validatesExpiry(claims) && o.zeroExpiry(t) {
+		return ErrTokenExpired
+	}
+
+	return validateClaims(claims, o)
+}
+
+// validatesExpiry reports whether validateClaims checks the 'exp' claim of
+// claims, as it does for types that are or embed Claims and for types with a
+// `jwt:"exp"` field not implementing Claimer themselves.
+func validatesExpiry(claims any) bool {
+	if _, ok := claims.(claimsValidator); ok {
+		return true
+	}
+
+	if _, ok := claims.(Claimer); ok {
+		return false
+	}
+
+	return hasTaggedClaim(claims, "exp")
+}
+
+// hasOwnValid reports whether t, a type embedding Claims, declares a Valid
+// method rather than having the one promoted from Claims. Promoted methods are
+// compiler-generated wrappers, so the embedded field providing Valid is
+// followed until a declared method or Claims itself is reached.
+func hasOwnValid(t reflect.Type) bool {
+	claimsType := reflect.TypeOf(&Claims{})
+
+	for t != claimsType {
+		if declaresValid(t) || t.Kind() == reflect.Ptr && declaresValid(t.Elem()) {
+			return true
+		}
+
+		if t = validField(t); t == nil {
+			return false
+		}
+	}
+
+	return false
+}
+
+// declaresValid reports whether the Valid method of t is declared in source
+// rather than generated for a promoted or pointer-receiver method.
+func declaresValid(t reflect.Type) bool {
+	m, ok := t.MethodByName("Valid")
+
+	if !ok {
+		return false
+	}
+
+	fn := runtime.FuncForPC(m.Func.Pointer())
+
+	if fn == nil {
+		return false
+	}
+
+	file, _ := fn.FileLine(fn.Entry())
+
+	return file != "<autogenerated>"
+}
+
+// validField returns the pointer type of the embedded field of t that
+// provides its Valid method, or nil if there is none.
+func validField(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.Anonymous {
+			continue
+		}
+
+		ft := field.Type
+
+		if ft.Kind() != reflect.Ptr {
+			ft = reflect.PtrTo(ft)
+		}
+
+		if _, ok := ft.MethodByName("Valid"); ok {
+			return ft
+		}
+	}
+
+	return nil
+}
+
+// indirectClaims strips pointer layers from claims down to the last pointer,
+// so that a **T target, such as the one ParseInto[*T] decodes into, is
+// validated like a *T.
+func indirectClaims(claims any) any {
+	rv := reflect.ValueOf(claims)
+
+	if !rv.IsValid() {
+		return claims
+	}
+
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr && !rv.Elem().IsNil() {
+		rv = rv.Elem()
+	}
+
+	return rv.Interface()
+}
+
+// validateClaims validates the time claims of types that are or embed Claims,
+// implement Claimer, or carry `jwt` struct tags, in that order of preference.
+// The time claims of types embedding Claims are validated with the options,
+// then their own Valid method, if any, is called; the Valid promoted from
+// Claims would check them again without the options.
+func validateClaims(claims any, o *options) error {
+	switch v := claims.(type) {
+	case *Claims:
+		return v.validate(o)
+	case claimsValidator:
+		if err := v.validate(o); err != nil {
 			return err
 		}
+
+		if claimer, ok := v.(Claimer); ok && hasOwnValid(reflect.TypeOf(v)) {
+			return claimer.Valid()
+		}
+
+		return nil
+	case Claimer:
+		return v.Valid()
+	}
+
+	if c, ok := taggedClaims(claims, o); ok {
+		return c.validate(o)
 	}
 
 	return nil
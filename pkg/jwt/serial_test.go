@@ -1,6 +1,16 @@
 package jwt
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -248,7 +258,7 @@ func TestMarshalUnmarshalRoundTrip(t *testing.T) {
 		Claims: Claims{
 			Issuer:    "test-issuer",
 			Subject:   "user123",
-			Audience:  "test-audience",
+			Audience:  Audience{"test-audience"},
 			ExpiresAt: time.Now().Add(1 * time.Hour).Unix(),
 			NotBefore: time.Now().Add(-1 * time.Minute).Unix(),
 			IssuedAt:  time.Now().Add(-1 * time.Minute).Unix(),
@@ -284,7 +294,7 @@ func TestMarshalUnmarshalRoundTrip(t *testing.T) {
 		t.Errorf("Subject = %v, want %v", decoded.Subject, original.Subject)
 	}
 
-	if decoded.Audience != original.Audience {
+	if !reflect.DeepEqual(decoded.Audience, original.Audience) {
 		t.Errorf("Audience = %v, want %v", decoded.Audience, original.Audience)
 	}
 
@@ -346,6 +356,41 @@ func TestUnmarshalTypeValidation(t *testing.T) {
 	})
 }
 
+// adminClaims embeds Claims and defines its own Valid
+type adminClaims struct {
+	Claims
+	Admin bool `json:"admin"`
+}
+
+var errNotAdmin = errors.New("not admin")
+
+func (c *adminClaims) Valid() error {
+	if !c.Admin {
+		return errNotAdmin
+	}
+
+	return nil
+}
+
+// valueAdminClaims defines its own Valid on a value receiver
+type valueAdminClaims struct {
+	Claims
+	Admin bool `json:"admin"`
+}
+
+func (c valueAdminClaims) Valid() error {
+	if !c.Admin {
+		return errNotAdmin
+	}
+
+	return nil
+}
+
+// nestedAdminClaims inherits the own Valid of adminClaims
+type nestedAdminClaims struct {
+	adminClaims
+}
+
 // TestClaimerInterface tests the Claimer interface validation
 func TestClaimerInterface(t *testing.T) {
 	secret := []byte("test-secret")
@@ -383,6 +428,39 @@ func TestClaimerInterface(t *testing.T) {
 		}
 	})
 
+	t.Run("own Valid of a type embedding Claims is called", func(t *testing.T) {
+		token, _ := Marshal(header, adminClaims{Claims: Claims{Subject: "user123"}}, secret)
+
+		if err := Unmarshal(token, &adminClaims{}, secret); err != errNotAdmin {
+			t.Errorf("Unmarshal() error = %v, want %v", err, errNotAdmin)
+		}
+	})
+
+	t.Run("own Valid through a value receiver or a nested type is called", func(t *testing.T) {
+		token, _ := Marshal(header, adminClaims{Claims: Claims{Subject: "user123"}}, secret)
+
+		if err := Unmarshal(token, &valueAdminClaims{}, secret); err != errNotAdmin {
+			t.Errorf("Unmarshal() into valueAdminClaims error = %v, want %v", err, errNotAdmin)
+		}
+
+		if err := Unmarshal(token, &nestedAdminClaims{}, secret); err != errNotAdmin {
+			t.Errorf("Unmarshal() into nestedAdminClaims error = %v, want %v", err, errNotAdmin)
+		}
+	})
+
+	t.Run("embedded time claims honor the options", func(t *testing.T) {
+		expiresAt := time.Now().Add(-1 * time.Hour)
+		token, _ := Marshal(header, adminClaims{Claims: Claims{ExpiresAt: expiresAt.Unix()}, Admin: true}, secret)
+
+		if err := Unmarshal(token, &adminClaims{}, secret, WithNow(expiresAt.Add(-time.Minute))); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+
+		if err := Unmarshal(token, &adminClaims{}, secret); err != ErrTokenExpired {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+		}
+	})
+
 	t.Run("custom claims without Claimer interface", func(t *testing.T) {
 		type CustomClaims struct {
 			UserID int `json:"user_id"`
@@ -439,6 +517,700 @@ func TestMultipleAlgorithms(t *testing.T) {
 	}
 }
 
+// TestUnmarshalStrict verifies the strict profile rejects tokens outside the secure baseline
+func TestUnmarshalStrict(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Now()
+
+	valid := Claims{
+		Subject:   "user123",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		IssuedAt:  now.Unix(),
+	}
+
+	tests := []struct {
+		name    string
+		header  Header
+		claims  Claims
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			header:  Header{Alg: HS256},
+			claims:  valid,
+			wantErr: nil,
+		},
+		{
+			name:    "missing exp",
+			header:  Header{Alg: HS256},
+			claims:  Claims{Subject: "user123", IssuedAt: now.Unix()},
+			wantErr: ErrMissingClaim,
+		},
+		{
+			name:    "missing iat",
+			header:  Header{Alg: HS256},
+			claims:  Claims{Subject: "user123", ExpiresAt: now.Add(time.Hour).Unix()},
+			wantErr: ErrMissingClaim,
+		},
+		{
+			name:    "expired",
+			header:  Header{Alg: HS256},
+			claims:  Claims{ExpiresAt: now.Add(-time.Hour).Unix(), IssuedAt: now.Add(-2 * time.Hour).Unix()},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "lowercase algorithm",
+			header:  Header{Alg: "hs256"},
+			claims:  valid,
+			wantErr: ErrUnexpectedAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(tt.header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var decoded Claims
+
+			if err := UnmarshalStrict(token, &decoded, secret); err != tt.wantErr {
+				t.Errorf("UnmarshalStrict() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("asymmetric algorithms", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		for _, tt := range []struct {
+			alg         string
+			key, verify any
+		}{
+			{alg: RS256, key: rsaKey, verify: &rsaKey.PublicKey},
+			{alg: ES256, key: ecKey, verify: &ecKey.PublicKey},
+		} {
+			token, _ := Marshal(Header{Alg: tt.alg}, valid, tt.key)
+
+			if err := UnmarshalStrict(token, &Claims{}, tt.verify); err != nil {
+				t.Errorf("UnmarshalStrict() with %s error = %v", tt.alg, err)
+			}
+		}
+	})
+
+	t.Run("none algorithm with the opt-in", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: None}, valid, UnsafeAllowNoneSignature)
+
+		if err := UnmarshalStrict(token, &Claims{}, UnsafeAllowNoneSignature); err != ErrUnexpectedAlgorithm {
+			t.Errorf("UnmarshalStrict() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+
+	t.Run("none algorithm", func(t *testing.T) {
+		header := encodeJWTBase64([]byte(`{"alg":"none","typ":"JWT"}`))
+		payload := encodeJWTBase64([]byte(`{"exp":9999999999,"iat":1}`))
+
+		if err := UnmarshalStrict(header+"."+payload+".", &Claims{}, secret); err != ErrUnexpectedAlgorithm {
+			t.Errorf("UnmarshalStrict() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+
+	t.Run("options add checks", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, valid, secret)
+
+		if err := UnmarshalStrict(token, &Claims{}, secret, WithIssuer("issuer")); err != ErrInvalidIssuer {
+			t.Errorf("UnmarshalStrict() error = %v, want %v", err, ErrInvalidIssuer)
+		}
+	})
+}
+
+// TestUnmarshalLeavesTargetOnError verifies failed validation never populates the caller's claims
+func TestUnmarshalLeavesTargetOnError(t *testing.T) {
+	secret := []byte("test-secret")
+
+	type CustomClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	expired, _ := Marshal(Header{Alg: HS256}, CustomClaims{
+		Claims: Claims{Subject: "attacker", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+		Role:   "admin",
+	}, secret)
+
+	t.Run("expired token", func(t *testing.T) {
+		decoded := CustomClaims{Role: "guest"}
+
+		if err := Unmarshal(expired, &decoded, secret); err != ErrTokenExpired {
+			t.Fatalf("Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+		}
+
+		if want := (CustomClaims{Role: "guest"}); !reflect.DeepEqual(decoded, want) {
+			t.Errorf("decoded = %+v, want untouched %+v", decoded, want)
+		}
+	})
+
+	t.Run("map target", func(t *testing.T) {
+		decoded := map[string]any{"kept": true}
+
+		if err := Unmarshal(expired, &decoded, secret, WithIssuer("issuer")); err != ErrInvalidIssuer {
+			t.Fatalf("Unmarshal() error = %v, want %v", err, ErrInvalidIssuer)
+		}
+
+		if want := map[string]any{"kept": true}; !reflect.DeepEqual(decoded, want) {
+			t.Errorf("decoded = %v, want untouched %v", decoded, want)
+		}
+	})
+
+	t.Run("populated on success", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, CustomClaims{Claims: Claims{Subject: "user123"}, Role: "admin"}, secret)
+
+		decoded := CustomClaims{Role: "guest"}
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.Subject != "user123" || decoded.Role != "admin" {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	})
+}
+
+// TestUnmarshalMulti verifies the key is selected by the token algorithm
+func TestUnmarshalMulti(t *testing.T) {
+	hs256Secret := []byte("hs256-secret")
+	hs512Secret := []byte("hs512-secret")
+
+	keys := map[string]any{
+		HS256: hs256Secret,
+		HS512: SymmetricKeySet{"v1": hs512Secret},
+	}
+
+	hs256, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, hs256Secret)
+	hs512, _ := Marshal(Header{Alg: HS512, Kid: "v1"}, Claims{Subject: "user123"}, hs512Secret)
+	hs384, _ := Marshal(Header{Alg: HS384}, Claims{Subject: "user123"}, hs256Secret)
+	swapped, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, hs512Secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		keys    map[string]any
+		opts    []Option
+		wantErr error
+	}{
+		{name: "first algorithm", token: hs256, keys: keys},
+		{name: "key set for an algorithm", token: hs512, keys: keys},
+		{name: "unregistered algorithm", token: hs384, keys: keys, wantErr: ErrUnexpectedAlgorithm},
+		{name: "key of another algorithm", token: swapped, keys: keys, wantErr: ErrSignatureMismatch},
+		{name: "key type mismatch", token: hs256, keys: map[string]any{HS256: "not-bytes"}, wantErr: ErrInvalidKeyType},
+		{name: "no keys", token: hs256, keys: map[string]any{}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "allowlist narrowed", token: hs256, keys: keys, opts: []Option{WithAllowedAlgorithms(HS512)}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "allowlist widened", token: hs384, keys: keys, opts: []Option{WithAllowedAlgorithms(HS384)}, wantErr: ErrUnexpectedAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims Claims
+
+			if err := UnmarshalMulti(tt.token, &claims, tt.keys, tt.opts...); err != tt.wantErr {
+				t.Fatalf("UnmarshalMulti() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && claims.Subject != "user123" {
+				t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+			}
+		})
+	}
+}
+
+// TestUnmarshalAllowExpired verifies expired but authentic tokens decode without error
+func TestUnmarshalAllowExpired(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	type CustomClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	tests := []struct {
+		name        string
+		claims      CustomClaims
+		key         []byte
+		opts        []Option
+		wantExpired bool
+		wantErr     error
+	}{
+		{
+			name:        "valid token",
+			claims:      CustomClaims{Claims: Claims{Subject: "user123", ExpiresAt: now.Add(time.Hour).Unix()}, Role: "admin"},
+			key:         secret,
+			wantExpired: false,
+		},
+		{
+			name:        "expired token",
+			claims:      CustomClaims{Claims: Claims{Subject: "user123", ExpiresAt: now.Add(-time.Hour).Unix()}, Role: "admin"},
+			key:         secret,
+			wantExpired: true,
+		},
+		{
+			name:        "token without exp",
+			claims:      CustomClaims{Claims: Claims{Subject: "user123"}, Role: "admin"},
+			key:         secret,
+			wantExpired: false,
+		},
+		{
+			name:    "wrong secret",
+			claims:  CustomClaims{Claims: Claims{ExpiresAt: now.Add(-time.Hour).Unix()}},
+			key:     []byte("wrong"),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "expired and not valid yet",
+			claims:  CustomClaims{Claims: Claims{ExpiresAt: now.Add(-time.Hour).Unix(), NotBefore: now.Add(time.Hour).Unix()}},
+			key:     secret,
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:    "expired with another failure",
+			claims:  CustomClaims{Claims: Claims{Issuer: "other", ExpiresAt: now.Add(-time.Hour).Unix()}},
+			key:     secret,
+			opts:    []Option{WithIssuer("issuer")},
+			wantErr: ErrInvalidIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256}, tt.claims, secret)
+
+			var decoded CustomClaims
+
+			expired, err := UnmarshalAllowExpired(token, &decoded, tt.key, append(tt.opts, WithNow(now))...)
+
+			if err != tt.wantErr {
+				t.Fatalf("UnmarshalAllowExpired() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if expired != tt.wantExpired {
+				t.Errorf("expired = %v, want %v", expired, tt.wantExpired)
+			}
+
+			if err == nil && !reflect.DeepEqual(decoded, tt.claims) {
+				t.Errorf("decoded = %+v, want %+v", decoded, tt.claims)
+			}
+		})
+	}
+}
+
+// taggedExpiry carries its expiry in a `jwt` tagged field
+type taggedExpiry struct {
+	Expiry int64 `json:"exp" jwt:"exp"`
+}
+
+// TestExplicitZeroExpiry verifies an explicit exp of 0 is expired, unlike an absent one
+func TestExplicitZeroExpiry(t *testing.T) {
+	secret := []byte("test-secret")
+
+	sign := func(payload string) string {
+		header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		encoded := encodeJWTBase64([]byte(payload))
+		signature, _ := ComputeSignature(header+"."+encoded, HS256, secret)
+		return header + "." + encoded + "." + signature
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		claims  any
+		opts    []Option
+		wantErr error
+	}{
+		{name: "absent exp", payload: `{"sub":"user123"}`, claims: &Claims{}},
+		{name: "explicit zero exp", payload: `{"sub":"user123","exp":0}`, claims: &Claims{}, wantErr: ErrTokenExpired},
+		{name: "null exp", payload: `{"sub":"user123","exp":null}`, claims: &Claims{}},
+		{name: "embedding claims", payload: `{"exp":0}`, claims: &struct{ Claims }{}, wantErr: ErrTokenExpired},
+		{name: "zero read as unset", payload: `{"exp":0}`, claims: &Claims{}, opts: []Option{WithZeroExpiryUnset()}},
+		{name: "map claims are not validated", payload: `{"exp":0}`, claims: &map[string]any{}},
+		{name: "escaped exp name", payload: `{"\u0065xp":0}`, claims: &Claims{}, wantErr: ErrTokenExpired},
+		{name: "negative exp", payload: `{"exp":-1}`, claims: &Claims{}, wantErr: ErrTokenExpired},
+		{name: "tagged zero exp", payload: `{"exp":0}`, claims: &taggedExpiry{}, wantErr: ErrTokenExpired},
+		{name: "tagged negative exp", payload: `{"exp":-1}`, claims: &taggedExpiry{}, wantErr: ErrTokenExpired},
+		{name: "tagged absent exp", payload: `{"sub":"user123"}`, claims: &taggedExpiry{}},
+		{name: "tags without exp", payload: `{"exp":0}`, claims: &struct {
+			Subject string `json:"sub" jwt:"sub"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(sign(tt.payload), tt.claims, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("reported as expired when allowed", func(t *testing.T) {
+		for _, payload := range []string{`{"exp":0}`, `{"exp":-1}`} {
+			expired, err := UnmarshalAllowExpired(sign(payload), &Claims{}, secret)
+
+			if err != nil || !expired {
+				t.Errorf("UnmarshalAllowExpired(%s) = %v, %v, want true, nil", payload, expired, err)
+			}
+		}
+	})
+
+	t.Run("diagnosed as expired", func(t *testing.T) {
+		_, errs := Diagnose(sign(`{"exp":0}`), secret)
+
+		if len(errs) != 1 || errs[0] != ErrTokenExpired {
+			t.Errorf("Diagnose() = %v, want [%v]", errs, ErrTokenExpired)
+		}
+	})
+}
+
+// TestIsValid tests the boolean verification helper
+func TestIsValid(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256}
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	valid, _ := Marshal(header, Claims{Issuer: "issuer", ExpiresAt: time.Now().Add(time.Hour).Unix()}, secret)
+	expiring, _ := Marshal(header, Claims{ExpiresAt: now.Add(time.Hour).Unix()}, secret)
+
+	tests := []struct {
+		name  string
+		token string
+		key   any
+		opts  []Option
+		want  bool
+	}{
+		{name: "valid token", token: valid, key: secret, want: true},
+		{name: "wrong secret", token: valid, key: []byte("other"), want: false},
+		{name: "malformed token", token: "a.b.c", key: secret, want: false},
+		{name: "expired by the real clock", token: expiring, key: secret, want: false},
+		{name: "valid at a fixed instant", token: expiring, key: secret, opts: []Option{WithNow(now)}, want: true},
+		{name: "issuer option applies", token: valid, key: secret, opts: []Option{WithIssuer("other")}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValid(tt.token, tt.key, tt.opts...); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVerifiedAlgorithm verifies the algorithm is only reported for valid tokens
+func TestVerifiedAlgorithm(t *testing.T) {
+	secret := []byte("test-secret")
+
+	hs384, _ := Marshal(Header{Alg: HS384}, Claims{Subject: "user123"}, secret)
+	expired, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: 1}, secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		key     any
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{name: "valid token", token: hs384, key: secret, want: HS384},
+		{name: "allowed algorithm", token: hs384, key: secret, opts: []Option{WithAllowedAlgorithms(HS384)}, want: HS384},
+		{name: "disallowed algorithm", token: hs384, key: secret, opts: []Option{WithAllowedAlgorithms(HS256)}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "wrong secret", token: hs384, key: []byte("other"), wantErr: ErrSignatureMismatch},
+		{name: "expired token", token: expired, key: secret, wantErr: ErrTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifiedAlgorithm(tt.token, tt.key, tt.opts...)
+
+			if err != tt.wantErr {
+				t.Fatalf("VerifiedAlgorithm() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("VerifiedAlgorithm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseInto verifies typed claims and the header are returned only for valid tokens
+func TestParseInto(t *testing.T) {
+	type customClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	secret := []byte("test-secret")
+	claims := customClaims{Claims: Claims{Issuer: "issuer", Subject: "user123"}, Role: "admin"}
+
+	token, _ := Marshal(Header{Alg: HS384, Kid: "key-1"}, claims, secret)
+
+	t.Run("typed claims and header", func(t *testing.T) {
+		decoded, header, err := ParseInto[customClaims](token, secret, WithIssuer("issuer"))
+
+		if err != nil {
+			t.Fatalf("ParseInto() error = %v", err)
+		}
+
+		if decoded.Subject != "user123" || decoded.Role != "admin" {
+			t.Errorf("claims = %+v, want %+v", decoded, claims)
+		}
+
+		if header != (Header{Alg: HS384, Typ: JWT, Kid: "key-1"}) {
+			t.Errorf("header = %+v, want HS384 JWT key-1", header)
+		}
+	})
+
+	t.Run("map claims", func(t *testing.T) {
+		decoded, _, err := ParseInto[map[string]any](token, secret)
+
+		if err != nil {
+			t.Fatalf("ParseInto() error = %v", err)
+		}
+
+		if decoded["role"] != "admin" {
+			t.Errorf("role = %v, want %q", decoded["role"], "admin")
+		}
+	})
+
+	t.Run("key set and asymmetric keys", func(t *testing.T) {
+		if decoded, _, err := ParseInto[customClaims](token, SymmetricKeySet{"key-1": secret}); err != nil || decoded.Role != "admin" {
+			t.Errorf("ParseInto() with a key set = %+v, %v", decoded, err)
+		}
+
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		signed, _ := Marshal(Header{Alg: EdDSA}, claims, edKey)
+
+		if decoded, _, err := ParseInto[customClaims](signed, edKey.Public()); err != nil || decoded.Role != "admin" {
+			t.Errorf("ParseInto() with an Ed25519 key = %+v, %v", decoded, err)
+		}
+	})
+
+	t.Run("pointer claims are validated", func(t *testing.T) {
+		expired, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: time.Now().Add(-time.Hour).Unix()}, secret)
+
+		if _, _, err := ParseInto[*Claims](expired, secret); err != ErrTokenExpired {
+			t.Errorf("ParseInto[*Claims]() error = %v, want %v", err, ErrTokenExpired)
+		}
+
+		if _, _, err := ParseInto[*customClaims](expired, secret); err != ErrTokenExpired {
+			t.Errorf("ParseInto[*customClaims]() error = %v, want %v", err, ErrTokenExpired)
+		}
+
+		var target *customClaims
+
+		if err := Unmarshal(expired, &target, secret); err != ErrTokenExpired {
+			t.Errorf("Unmarshal() into **customClaims error = %v, want %v", err, ErrTokenExpired)
+		}
+
+		decoded, _, err := ParseInto[*customClaims](token, secret)
+
+		if err != nil || decoded.Role != "admin" {
+			t.Errorf("ParseInto[*customClaims]() = %+v, %v", decoded, err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		opts    []Option
+		wantErr error
+	}{
+		{name: "wrong secret", secret: []byte("other"), wantErr: ErrSignatureMismatch},
+		{name: "failed validation", secret: secret, opts: []Option{WithIssuer("other")}, wantErr: ErrInvalidIssuer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, header, err := ParseInto[customClaims](token, tt.secret, tt.opts...)
+
+			if err != tt.wantErr {
+				t.Fatalf("ParseInto() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(decoded, customClaims{}) || header != (Header{}) {
+				t.Errorf("ParseInto() = %+v, %+v, want zero values", decoded, header)
+			}
+		})
+	}
+}
+
+// TestUnmarshalJWS tests plain JWS verification without JWT validation
+func TestUnmarshalJWS(t *testing.T) {
+	secret := []byte("test-secret")
+
+	t.Run("non-JWT type is accepted", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256, Typ: "JOSE"}, map[string]string{"msg": "hello"}, secret)
+
+		var decoded map[string]string
+
+		if err := UnmarshalJWS(token, &decoded, secret); err != nil {
+			t.Fatalf("UnmarshalJWS() error = %v", err)
+		}
+
+		if decoded["msg"] != "hello" {
+			t.Errorf("msg = %v, want %v", decoded["msg"], "hello")
+		}
+
+		if err := Unmarshal(token, &decoded, secret); err == nil {
+			t.Error("Unmarshal() should reject a non-JWT type")
+		}
+	})
+
+	t.Run("claims are not validated", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: time.Now().Add(-1 * time.Hour).Unix()}, secret)
+
+		var decoded Claims
+
+		if err := UnmarshalJWS(token, &decoded, secret); err != nil {
+			t.Errorf("UnmarshalJWS() error = %v", err)
+		}
+	})
+
+	t.Run("signature is verified", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "test"}, []byte("other-secret"))
+
+		var decoded Claims
+
+		if err := UnmarshalJWS(token, &decoded, secret); err != ErrSignatureMismatch {
+			t.Errorf("UnmarshalJWS() error = %v, want %v", err, ErrSignatureMismatch)
+		}
+	})
+}
+
+// TestInvalidKeyType tests that keys not matching the algorithm are rejected
+func TestInvalidKeyType(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	claims := Claims{Subject: "test"}
+
+	keys := []struct {
+		name string
+		key  any
+	}{
+		{name: "nil key", key: nil},
+		{name: "string key", key: "test-secret"},
+		{name: "pointer to secret", key: &secret},
+	}
+
+	token, err := Marshal(header, claims, secret)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, tt := range keys {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(header, claims, tt.key); err != ErrInvalidKeyType {
+				t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidKeyType)
+			}
+
+			var decoded Claims
+
+			if err := Unmarshal(token, &decoded, tt.key); err != ErrInvalidKeyType {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidKeyType)
+			}
+		})
+	}
+}
+
+// TestUnmarshalUnsupportedAlgorithm verifies unknown algorithms surface before any signature work
+func TestUnmarshalUnsupportedAlgorithm(t *testing.T) {
+	payload := encodeJWTBase64([]byte(`{"sub":"user123"}`))
+
+	tests := []struct {
+		name      string
+		alg       string
+		signature string
+		key       any
+	}{
+		{name: "PS256", alg: "PS256", signature: encodeJWTBase64(make([]byte, 256)), key: []byte("secret")},
+		{name: "PS384", alg: "PS384", signature: encodeJWTBase64(make([]byte, 384)), key: []byte("secret")},
+		{name: "none", alg: "none", signature: "", key: []byte("secret")},
+		{name: "unknown", alg: "XS999", signature: "c2ln", key: []byte("secret")},
+		{name: "key of another type", alg: "PS256", signature: "c2ln", key: "not-a-secret"},
+		{name: "unknown key id", alg: "PS512", signature: "c2ln", key: SymmetricKeySet{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := encodeJWTBase64([]byte(`{"alg":"` + tt.alg + `","typ":"JWT"}`))
+
+			err := Unmarshal(header+"."+payload+"."+tt.signature, &Claims{}, tt.key)
+
+			if err != (unsupportedAlgorithmError{alg: tt.alg}) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, unsupportedAlgorithmError{alg: tt.alg})
+			}
+
+			if !IsUnsupportedAlgorithm(err) {
+				t.Errorf("IsUnsupportedAlgorithm(%v) = false, want true", err)
+			}
+		})
+	}
+
+	t.Run("other errors", func(t *testing.T) {
+		for _, err := range []error{nil, ErrSignatureMismatch, ErrUnexpectedAlgorithm, unsupportedTypeError{typ: "RS256"}} {
+			if IsUnsupportedAlgorithm(err) {
+				t.Errorf("IsUnsupportedAlgorithm(%v) = true, want false", err)
+			}
+		}
+	})
+
+	t.Run("wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("verify upstream token: %w", unsupportedAlgorithmError{alg: "RS256"})
+
+		if !IsUnsupportedAlgorithm(err) {
+			t.Errorf("IsUnsupportedAlgorithm(%v) = false, want true", err)
+		}
+	})
+}
+
+// TestNilClaimsTarget verifies nil claims targets are rejected with ErrNilClaimsTarget
+func TestNilClaimsTarget(t *testing.T) {
+	secret := []byte("test-secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "test"}, secret)
+
+	type CustomClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	var claims *Claims
+	var custom *CustomClaims
+
+	targets := []struct {
+		name   string
+		claims any
+	}{
+		{name: "nil interface", claims: nil},
+		{name: "nil claims pointer", claims: claims},
+		{name: "nil custom claims pointer", claims: custom},
+	}
+
+	for _, tt := range targets {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(token, tt.claims, secret); err != ErrNilClaimsTarget {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrNilClaimsTarget)
+			}
+
+			if err := UnmarshalJWS(token, tt.claims, secret); err != ErrNilClaimsTarget {
+				t.Errorf("UnmarshalJWS() error = %v, want %v", err, ErrNilClaimsTarget)
+			}
+		})
+	}
+}
+
 // BenchmarkMarshalClaims benchmarks marshaling with Claims struct
 func BenchmarkMarshalClaims(b *testing.B) {
 	secret := []byte("test-secret")
@@ -456,6 +1228,158 @@ func BenchmarkMarshalClaims(b *testing.B) {
 	}
 }
 
+// largeClaims is a realistic custom claims struct with about forty fields
+type largeClaims struct {
+	Claims
+	TenantID      string            `json:"tenant_id"`
+	OrgID         string            `json:"org_id"`
+	Email         string            `json:"email"`
+	EmailVerified bool              `json:"email_verified"`
+	Name          string            `json:"name"`
+	GivenName     string            `json:"given_name"`
+	FamilyName    string            `json:"family_name"`
+	Locale        string            `json:"locale"`
+	Zoneinfo      string            `json:"zoneinfo"`
+	Picture       string            `json:"picture"`
+	Roles         []string          `json:"roles"`
+	Groups        []string          `json:"groups"`
+	Scopes        []string          `json:"scope"`
+	Permissions   []string          `json:"permissions"`
+	SessionID     string            `json:"sid"`
+	ClientID      string            `json:"client_id"`
+	AuthTime      int64             `json:"auth_time"`
+	AMR           []string          `json:"amr"`
+	ACR           string            `json:"acr"`
+	Nonce         string            `json:"nonce"`
+	AtHash        string            `json:"at_hash"`
+	DeviceID      string            `json:"device_id"`
+	IPAddress     string            `json:"ip"`
+	UserAgent     string            `json:"ua"`
+	Country       string            `json:"country"`
+	Region        string            `json:"region"`
+	Plan          string            `json:"plan"`
+	Seats         int               `json:"seats"`
+	Quota         int64             `json:"quota"`
+	Beta          bool              `json:"beta"`
+	MFA           bool              `json:"mfa"`
+	Impersonator  string            `json:"act,omitempty"`
+	Department    string            `json:"department"`
+	Manager       string            `json:"manager"`
+	EmployeeID    string            `json:"employee_id"`
+	CostCenter    string            `json:"cost_center"`
+	Features      map[string]bool   `json:"features"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// newLargeClaims returns fully populated largeClaims
+func newLargeClaims() largeClaims {
+	return largeClaims{
+		Claims: Claims{
+			Issuer:    "https://auth.example.com/",
+			Subject:   "user-6f1c2a9e-0b7d-4c43-9a1e-2d5f8b3c7e10",
+			Audience:  Audience{"https://api.example.com", "https://admin.example.com"},
+			ExpiresAt: time.Now().Add(1 * time.Hour).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			ID:        "2b8e4f6a-9c1d-4e7b-8a3f-5d6c7b8a9e0f",
+		},
+		TenantID:      "tenant-42",
+		OrgID:         "org-9001",
+		Email:         "jane.doe@example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+		Locale:        "en-US",
+		Zoneinfo:      "Europe/Berlin",
+		Picture:       "https://cdn.example.com/avatars/jane.png",
+		Roles:         []string{"admin", "editor", "viewer"},
+		Groups:        []string{"engineering", "platform", "on-call"},
+		Scopes:        []string{"read:users", "write:users", "read:billing"},
+		Permissions:   []string{"users.create", "users.delete", "billing.view", "reports.export"},
+		SessionID:     "sess-7d9e1f2a",
+		ClientID:      "web-dashboard",
+		AuthTime:      time.Now().Unix(),
+		AMR:           []string{"pwd", "otp"},
+		ACR:           "urn:mace:incommon:iap:silver",
+		Nonce:         "n-0S6_WzA2Mj",
+		AtHash:        "77QmUPtjPfzWtF2AnpK9RQ",
+		DeviceID:      "device-3c4d5e6f",
+		IPAddress:     "203.0.113.42",
+		UserAgent:     "Mozilla/5.0 (X11; Linux x86_64)",
+		Country:       "DE",
+		Region:        "eu-central-1",
+		Plan:          "enterprise",
+		Seats:         250,
+		Quota:         1 << 40,
+		Beta:          true,
+		MFA:           true,
+		Department:    "R&D",
+		Manager:       "user-1a2b3c4d",
+		EmployeeID:    "E-102938",
+		CostCenter:    "CC-4410",
+		Features:      map[string]bool{"dark_mode": true, "new_editor": false, "audit_log": true},
+		Metadata:      map[string]string{"signup_source": "sso", "tier": "gold"},
+	}
+}
+
+// TestMarshalLargeClaims verifies large custom claims encode to the plain concatenation of the segments
+func TestMarshalLargeClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := newLargeClaims()
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(claims); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := encodeJWTBase64(bytes.TrimSpace(buf.Bytes()))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		context string
+	}{
+		{name: "without signing context"},
+		{name: "with signing context", opts: []Option{WithSigningContext([]byte("ctx"))}, context: ".ctx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, _ := ComputeSignature(header+"."+payload+tt.context, HS256, secret)
+			want := header + "." + payload + "." + signature
+
+			got, err := Marshal(Header{Alg: HS256}, claims, secret, tt.opts...)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			if got != want {
+				t.Errorf("Marshal() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// BenchmarkMarshalLargeClaims benchmarks marshaling a custom struct with many fields
+func BenchmarkMarshalLargeClaims(b *testing.B) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	claims := newLargeClaims()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = Marshal(header, claims, secret)
+	}
+}
+
 // BenchmarkUnmarshalClaims benchmarks unmarshaling with Claims struct
 func BenchmarkUnmarshalClaims(b *testing.B) {
 	secret := []byte("test-secret")
@@ -476,6 +1400,37 @@ func BenchmarkUnmarshalClaims(b *testing.B) {
 	}
 }
 
+// BenchmarkUnmarshalValidatedClaims benchmarks unmarshaling a custom struct
+// embedding Claims with validators reading the registered claims
+func BenchmarkUnmarshalValidatedClaims(b *testing.B) {
+	type customClaims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	claims := customClaims{
+		Claims: Claims{
+			Issuer:    "test-issuer",
+			Subject:   "user123",
+			Audience:  Audience{"api"},
+			ExpiresAt: time.Now().Add(1 * time.Hour).Unix(),
+		},
+		Role: "admin",
+	}
+
+	token, _ := Marshal(header, claims, secret)
+	opts := []Option{WithIssuer("test-issuer"), WithAudience("api")}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var decoded customClaims
+		_ = Unmarshal(token, &decoded, secret, opts...)
+	}
+}
+
 // BenchmarkMarshalMap benchmarks marshaling with map claims
 func BenchmarkMarshalMap(b *testing.B) {
 	secret := []byte("test-secret")
@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash.New
+	"encoding/hex"
+	"strconv"
+)
+
+// HashForAlg returns the hash function underlying the algorithm, for wiring
+// external signers that hash the signing input themselves. The algorithm name
+// is matched case-insensitively like the 'alg' header. EdDSA signs the message
+// with no separate hash step, so it is reported as unsupported.
+func HashForAlg(alg string) (crypto.Hash, error) {
+	method, err := methodForAlg(alg)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch m := method.(type) {
+	case hmacMethod:
+		return m.hash, nil
+	case rsaMethod:
+		return m.hash, nil
+	case ecdsaMethod:
+		return m.hash, nil
+	}
+
+	return 0, unsupportedAlgorithmError{alg: alg}
+}
+
+// ComputeSignature returns the base64url-encoded signature segment of the
+// signing input ("header.payload") for the algorithm, using the same signer as
+// Marshal. A key whose type does not match the algorithm yields
+// ErrInvalidKeyType.
+func ComputeSignature(signingInput string, alg string, key any) (string, error) {
+	header := Header{Alg: alg}
+
+	signature, err := header.sign(signingInput, key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return encodeJWTBase64(signature), nil
+}
+
+// RedactSignature returns a loggable fingerprint of a raw signature made of its
+// length, its first two bytes, and a truncated SHA-256 digest. It never reveals
+// enough of the signature to forge or replay it.
+func RedactSignature(signature []byte) string {
+	prefix := signature
+
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+
+	digest := sha256.Sum256(signature)
+
+	return "len=" + strconv.Itoa(len(signature)) + " prefix=" + hex.EncodeToString(prefix) + " sha256=" + hex.EncodeToString(digest[:4])
+}
+
+// Fingerprint returns the base64url-encoded SHA-256 digest of the whole
+// compact token. It is stable, needs no key, and cannot be reversed into the
+// token, so it can stand in for tokens in logs and indexes. It is meant for
+// correlation only: it does not verify the token or prove its authenticity.
+func Fingerprint(jws string) string {
+	digest := sha256.Sum256([]byte(jws))
+
+	return encodeJWTBase64(digest[:])
+}
@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// rfc7515HS256 is the HS256 example from RFC 7515 Appendix A.1
+var rfc7515HS256 = struct {
+	key, header, payload, signature string
+}{
+	key:       "AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow",
+	header:    "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9",
+	payload:   "eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ",
+	signature: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+}
+
+// TestHashForAlg verifies each algorithm maps to its stdlib hash
+func TestHashForAlg(t *testing.T) {
+	tests := []struct {
+		alg     string
+		want    crypto.Hash
+		wantErr bool
+	}{
+		{alg: HS256, want: crypto.SHA256},
+		{alg: HS384, want: crypto.SHA384},
+		{alg: HS512, want: crypto.SHA512},
+		{alg: RS256, want: crypto.SHA256},
+		{alg: RS384, want: crypto.SHA384},
+		{alg: RS512, want: crypto.SHA512},
+		{alg: ES256, want: crypto.SHA256},
+		{alg: ES384, want: crypto.SHA384},
+		{alg: ES512, want: crypto.SHA512},
+		{alg: EdDSA, wantErr: true},
+		{alg: "hs256", want: crypto.SHA256},
+		{alg: "none", wantErr: true},
+		{alg: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			got, err := HashForAlg(tt.alg)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HashForAlg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("HashForAlg() = %v, want %v", got, tt.want)
+			}
+
+			if !tt.wantErr && !got.Available() {
+				t.Errorf("%v is not linked into the binary", got)
+			}
+		})
+	}
+}
+
+// TestComputeSignature verifies signatures match the RFC vector and Marshal
+func TestComputeSignature(t *testing.T) {
+	t.Run("RFC 7515 vector", func(t *testing.T) {
+		key, _ := base64.RawURLEncoding.DecodeString(rfc7515HS256.key)
+
+		got, err := ComputeSignature(rfc7515HS256.header+"."+rfc7515HS256.payload, HS256, key)
+
+		if err != nil {
+			t.Fatalf("ComputeSignature() error = %v", err)
+		}
+
+		if got != rfc7515HS256.signature {
+			t.Errorf("ComputeSignature() = %q, want %q", got, rfc7515HS256.signature)
+		}
+	})
+
+	t.Run("matches Marshal", func(t *testing.T) {
+		secret := []byte("secret")
+
+		for _, alg := range []string{HS256, HS384, HS512} {
+			token, _ := Marshal(Header{Alg: alg}, Claims{Subject: "test"}, secret)
+			parts := strings.Split(token, ".")
+
+			got, err := ComputeSignature(parts[0]+"."+parts[1], alg, secret)
+
+			if err != nil {
+				t.Fatalf("ComputeSignature() error = %v", err)
+			}
+
+			if got != parts[2] {
+				t.Errorf("%s: ComputeSignature() = %q, want %q", alg, got, parts[2])
+			}
+		}
+	})
+
+	t.Run("invalid key type", func(t *testing.T) {
+		if _, err := ComputeSignature("a.b", HS256, "secret"); err != ErrInvalidKeyType {
+			t.Errorf("ComputeSignature() error = %v, want %v", err, ErrInvalidKeyType)
+		}
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		if _, err := ComputeSignature("a.b", "XS256", []byte("secret")); err == nil {
+			t.Error("ComputeSignature() should fail for an unsupported algorithm")
+		}
+	})
+}
+
+// TestRedactSignature verifies the fingerprint format
+func TestRedactSignature(t *testing.T) {
+	got := RedactSignature([]byte{0xde, 0xad, 0xbe, 0xef})
+	want := "len=4 prefix=dead sha256=5f78c332"
+
+	if got != want {
+		t.Errorf("RedactSignature() = %q, want %q", got, want)
+	}
+
+	if got := RedactSignature(nil); !strings.HasPrefix(got, "len=0 prefix= ") {
+		t.Errorf("RedactSignature(nil) = %q", got)
+	}
+}
+
+// TestFingerprint verifies fingerprints are stable and distinguish tokens
+func TestFingerprint(t *testing.T) {
+	secret := []byte("test-secret")
+
+	first, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+	second, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user456"}, secret)
+
+	got := Fingerprint(first)
+
+	if got != Fingerprint(first) {
+		t.Error("Fingerprint() is not stable")
+	}
+
+	if got == Fingerprint(second) {
+		t.Error("Fingerprint() is equal for different tokens")
+	}
+
+	if strings.Contains(got, ".") || len(got) != 43 {
+		t.Errorf("Fingerprint() = %q, want a 43-character base64url digest", got)
+	}
+
+	if want := "47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU"; Fingerprint("") != want {
+		t.Errorf("Fingerprint(\"\") = %q, want %q", Fingerprint(""), want)
+	}
+}
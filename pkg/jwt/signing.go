@@ -0,0 +1,442 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+)
+
+// signingMethod signs and verifies signing inputs for one algorithm.
+type signingMethod interface {
+	// sign returns the raw signature of message under key
+	sign(message []byte, key any) ([]byte, error)
+
+	// verify returns ErrSignatureMismatch unless signature is a valid
+	// signature of message under key
+	verify(message, signature []byte, key any) error
+
+	// checkLength rejects signatures whose length of n bytes cannot be valid
+	// at all, so malformed signatures fail before the key is resolved
+	checkLength(n int) error
+
+	// family returns the family of the keys the method signs with
+	family() keyFamily
+}
+
+// keyFamily groups the algorithms that share a kind of key
+type keyFamily int
+
+const (
+	unknownFamily keyFamily = iota
+	hmacFamily
+	rsaFamily
+	ecdsaFamily
+	eddsaFamily
+	noneFamily
+)
+
+// keyFamilyOf returns the family of key, or unknownFamily for types no
+// algorithm takes. A crypto.Signer belongs to the family of its public key.
+func keyFamilyOf(key any) keyFamily {
+	switch k := key.(type) {
+	case []byte:
+		return hmacFamily
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return rsaFamily
+	case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return ecdsaFamily
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return eddsaFamily
+	case unsafeNoneSignature:
+		return noneFamily
+	case crypto.Signer:
+		return keyFamilyOf(k.Public())
+	}
+
+	return unknownFamily
+}
+
+// checkKeyFamily guards against algorithm confusion, where a token names an
+// algorithm of another family than the verification key, such as HS256 with
+// the RSA public key used as the HMAC secret. Keys of unknown types are left to
+// the method, which rejects them with ErrInvalidKeyType.
+func checkKeyFamily(method signingMethod, key any) error {
+	if family := keyFamilyOf(key); family != unknownFamily && family != method.family() {
+		return ErrAlgorithmMismatch
+	}
+
+	return nil
+}
+
+// signingMethods maps each supported algorithm to its signing method
+var signingMethods = map[string]signingMethod{
+	HS256: hmacMethod{hash: crypto.SHA256},
+	HS384: hmacMethod{hash: crypto.SHA384},
+	HS512: hmacMethod{hash: crypto.SHA512},
+	RS256: rsaMethod{hash: crypto.SHA256},
+	RS384: rsaMethod{hash: crypto.SHA384},
+	RS512: rsaMethod{hash: crypto.SHA512},
+	ES256: ecdsaMethod{hash: crypto.SHA256, curve: elliptic.P256(), size: 32},
+	ES384: ecdsaMethod{hash: crypto.SHA384, curve: elliptic.P384(), size: 48},
+	ES512: ecdsaMethod{hash: crypto.SHA512, curve: elliptic.P521(), size: 66},
+
+	// Keys are uppercase since lookups are case-insensitive
+	strings.ToUpper(EdDSA): eddsaMethod{},
+	strings.ToUpper(None):  noneMethod{},
+}
+
+// UnsafeAllowNoneSignature is the key that opts into unsigned tokens. Marshal
+// emits an empty signature for the "none" algorithm only when given this key,
+// and Unmarshal accepts a "none" token only when given it; with any other key,
+// including a valid secret, "none" stays an unsupported algorithm. Unsigned
+// tokens carry no integrity protection, so it is meant for tests and debugging
+// and must never be passed where tokens come from untrusted parties.
+var UnsafeAllowNoneSignature unsafeNoneSignature
+
+// unsafeNoneSignature is the type of UnsafeAllowNoneSignature, unexported so
+// the opt-in cannot be produced by decoding or converting other values
+type unsafeNoneSignature struct{}
+
+// methodForAlg returns the signing method of an algorithm, matched
+// case-insensitively like the 'alg' header.
+func methodForAlg(alg string) (signingMethod, error) {
+	method, ok := signingMethods[strings.ToUpper(alg)]
+
+	if !ok {
+		return nil, unsupportedAlgorithmError{alg: alg}
+	}
+
+	return method, nil
+}
+
+// methodFor is methodForAlg for signing or verifying with key, before the key
+// is resolved: "none" is unsupported unless key is UnsafeAllowNoneSignature.
+func methodFor(alg string, key any) (signingMethod, error) {
+	method, err := methodForAlg(alg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := method.(noneMethod); ok {
+		if _, ok := key.(unsafeNoneSignature); !ok {
+			return nil, unsupportedAlgorithmError{alg: alg}
+		}
+	}
+
+	return method, nil
+}
+
+// hmacMethod implements HS256, HS384, and HS512 with a []byte secret.
+type hmacMethod struct {
+	hash crypto.Hash
+}
+
+func (m hmacMethod) sign(message []byte, key any) ([]byte, error) {
+	secret, ok := key.([]byte)
+
+	if !ok {
+		return nil, ErrInvalidKeyType
+	}
+
+	mac := hmac.New(m.hash.New, secret)
+	mac.Write(message)
+
+	return mac.Sum(nil), nil
+}
+
+func (m hmacMethod) verify(message, signature []byte, key any) error {
+	computed, err := m.sign(message, key)
+
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(computed, signature) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// An HMAC is exactly as long as its hash, so other lengths cannot match
+func (m hmacMethod) checkLength(n int) error {
+	if n != m.hash.Size() {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+func (hmacMethod) family() keyFamily {
+	return hmacFamily
+}
+
+// rsaMethod implements RS256, RS384, and RS512 (RSASSA-PKCS1-v1_5). It signs
+// with any crypto.Signer holding an RSA key, such as an *rsa.PrivateKey or a
+// key kept in an HSM, and verifies with an *rsa.PublicKey.
+type rsaMethod struct {
+	hash crypto.Hash
+}
+
+func (m rsaMethod) sign(message []byte, key any) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+
+	if !ok {
+		return nil, ErrInvalidKeyType
+	}
+
+	if _, ok := signer.Public().(*rsa.PublicKey); !ok {
+		return nil, ErrInvalidKeyType
+	}
+
+	return signer.Sign(rand.Reader, m.digest(message), m.hash)
+}
+
+func (m rsaMethod) verify(message, signature []byte, key any) error {
+	publicKey, ok := key.(*rsa.PublicKey)
+
+	if !ok {
+		return ErrInvalidKeyType
+	}
+
+	if err := rsa.VerifyPKCS1v15(publicKey, m.hash, m.digest(message), signature); err != nil {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// An RSA signature is as long as the modulus, which only the key knows
+func (m rsaMethod) checkLength(n int) error {
+	if n == 0 {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+func (rsaMethod) family() keyFamily {
+	return rsaFamily
+}
+
+func (m rsaMethod) digest(message []byte) []byte {
+	return digest(m.hash, message)
+}
+
+// ecdsaMethod implements ES256, ES384, and ES512 with the key on the curve of
+// the algorithm. Signatures are the JWS encoding of R and S, each left-padded
+// to the curve byte size and concatenated, rather than ASN.1 DER; acceptDER
+// lets verification fall back to DER.
+type ecdsaMethod struct {
+	hash      crypto.Hash
+	curve     elliptic.Curve
+	size      int
+	acceptDER bool
+}
+
+func (m ecdsaMethod) sign(message []byte, key any) ([]byte, error) {
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+
+	if !ok || privateKey.Curve != m.curve {
+		return nil, ErrInvalidKeyType
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest(m.hash, message))
+
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 2*m.size)
+	r.FillBytes(signature[:m.size])
+	s.FillBytes(signature[m.size:])
+
+	return signature, nil
+}
+
+func (m ecdsaMethod) verify(message, signature []byte, key any) error {
+	publicKey, ok := key.(*ecdsa.PublicKey)
+
+	if !ok || publicKey.Curve != m.curve {
+		return ErrInvalidKeyType
+	}
+
+	if err := m.checkLength(len(signature)); err != nil {
+		return err
+	}
+
+	hashed := digest(m.hash, message)
+
+	// R||S is tried first, even with DER accepted, as a DER signature of that
+	// exact length is possible but rare
+	if len(signature) == 2*m.size {
+		r := new(big.Int).SetBytes(signature[:m.size])
+		s := new(big.Int).SetBytes(signature[m.size:])
+
+		if ecdsa.Verify(publicKey, hashed, r, s) {
+			return nil
+		}
+	}
+
+	if m.acceptDER {
+		if r, s, ok := parseDERSignature(signature); ok && ecdsa.Verify(publicKey, hashed, r, s) {
+			return nil
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+// R and S have a fixed width, so any other length is malformed rather than
+// merely wrong and is never reinterpreted. With DER accepted, any length up to
+// that of a DER SEQUENCE of two curve-sized INTEGERs is let through to verify.
+func (m ecdsaMethod) checkLength(n int) error {
+	if n == 2*m.size || m.acceptDER && n >= minDERSignature && n <= maxDERSignature(m.size) {
+		return nil
+	}
+
+	return ErrInvalidToken
+}
+
+// minDERSignature is the length of a DER SEQUENCE of two one-byte INTEGERs
+const minDERSignature = 8
+
+// maxDERSignature returns the length of a DER SEQUENCE of two INTEGERs of size
+// bytes, each with a leading zero byte: two tag and length bytes per INTEGER
+// and up to three for the SEQUENCE, whose long-form length P-521 needs.
+func maxDERSignature(size int) int {
+	return 3 + 2*(2+size+1)
+}
+
+// parseDERSignature decodes an ASN.1 DER ECDSA signature. Only the canonical
+// encoding with no trailing data is accepted, so the same R and S cannot be
+// smuggled in several byte forms.
+func parseDERSignature(signature []byte) (r, s *big.Int, ok bool) {
+	var sig struct {
+		R, S *big.Int
+	}
+
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+		return nil, nil, false
+	}
+
+	if canonical, err := asn1.Marshal(sig); err != nil || !bytes.Equal(canonical, signature) {
+		return nil, nil, false
+	}
+
+	return sig.R, sig.S, true
+}
+
+// verificationMethod is methodFor adjusted by the options that change how
+// signatures are read, such as WithAcceptDERSignatures.
+func (o *options) verificationMethod(alg string, key any) (signingMethod, error) {
+	method, err := methodFor(alg, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if em, ok := method.(ecdsaMethod); ok && o.acceptDER {
+		em.acceptDER = true
+
+		return em, nil
+	}
+
+	return method, nil
+}
+
+func (ecdsaMethod) family() keyFamily {
+	return ecdsaFamily
+}
+
+// eddsaMethod implements EdDSA with Ed25519 keys. Ed25519 signs the message
+// itself, with no separate hash step.
+type eddsaMethod struct{}
+
+func (eddsaMethod) sign(message []byte, key any) ([]byte, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+
+	if !ok {
+		return nil, ErrInvalidKeyType
+	}
+
+	// ed25519.Sign panics on a malformed key
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	return ed25519.Sign(privateKey, message), nil
+}
+
+func (eddsaMethod) verify(message, signature []byte, key any) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+
+	if !ok {
+		return ErrInvalidKeyType
+	}
+
+	// ed25519.Verify panics on a malformed key
+	if len(publicKey) != ed25519.PublicKeySize {
+		return ErrInvalidKeySize
+	}
+
+	if !ed25519.Verify(publicKey, message, signature) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+func (eddsaMethod) checkLength(n int) error {
+	if n != ed25519.SignatureSize {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+func (eddsaMethod) family() keyFamily {
+	return eddsaFamily
+}
+
+// noneMethod implements the "none" algorithm of unsigned tokens, behind the
+// UnsafeAllowNoneSignature opt-in.
+type noneMethod struct{}
+
+func (noneMethod) sign(message []byte, key any) ([]byte, error) {
+	return []byte{}, nil
+}
+
+func (noneMethod) verify(message, signature []byte, key any) error {
+	return nil
+}
+
+// An unsigned token has an empty signature segment and nothing else
+func (noneMethod) checkLength(n int) error {
+	if n != 0 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func (noneMethod) family() keyFamily {
+	return noneFamily
+}
+
+// digest hashes message with h, for the methods that sign a digest
+func digest(h crypto.Hash, message []byte) []byte {
+	d := h.New()
+	d.Write(message)
+
+	return d.Sum(nil)
+}
@@ -0,0 +1,508 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// opaqueSigner hides an RSA private key behind crypto.Signer, as an HSM would
+type opaqueSigner struct {
+	key *rsa.PrivateKey
+}
+
+func (s opaqueSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.key.Sign(rand, digest, opts)
+}
+
+// TestRSARoundTrip verifies RS256, RS384, and RS512 tokens verify with the public key
+func TestRSARoundTrip(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	for _, alg := range []string{RS256, RS384, RS512} {
+		t.Run(alg, func(t *testing.T) {
+			token, err := Marshal(Header{Alg: alg}, Claims{Subject: "user123"}, privateKey)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var claims Claims
+
+			if err := Unmarshal(token, &claims, &privateKey.PublicKey); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if claims.Subject != "user123" {
+				t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+			}
+		})
+	}
+
+	t.Run("crypto.Signer", func(t *testing.T) {
+		token, err := Marshal(Header{Alg: RS256}, Claims{Subject: "user123"}, opaqueSigner{key: privateKey})
+
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		if err := Unmarshal(token, &Claims{}, &privateKey.PublicKey); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
+
+// TestRSARejection verifies tampered tokens, foreign keys, and mismatched key types fail
+func TestRSARejection(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	token, err := Marshal(Header{Alg: RS256}, Claims{Subject: "user123"}, privateKey)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	b64vals := b64values{}
+	_ = b64vals.unmarshal(token)
+
+	signature, _ := decodeJWTBase64(b64vals.signature)
+	signature[0] ^= 0xff
+
+	tampered := b64vals.header + "." + b64vals.payload + "." + encodeJWTBase64(signature)
+
+	tests := []struct {
+		name    string
+		token   string
+		key     any
+		wantErr error
+	}{
+		{name: "tampered signature", token: tampered, key: &privateKey.PublicKey, wantErr: ErrSignatureMismatch},
+		{name: "another public key", token: token, key: &otherKey.PublicKey, wantErr: ErrSignatureMismatch},
+		{name: "private key", token: token, key: privateKey, wantErr: ErrInvalidKeyType},
+		{name: "HMAC secret", token: token, key: []byte("secret"), wantErr: ErrAlgorithmMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, tt.key); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("signing with a public key", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: RS256}, Claims{}, &privateKey.PublicKey); err != ErrInvalidKeyType {
+			t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidKeyType)
+		}
+	})
+
+	t.Run("signing RS256 with a secret", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: RS256}, Claims{}, []byte("secret")); err != ErrInvalidKeyType {
+			t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidKeyType)
+		}
+	})
+}
+
+// rfc7515ES256 is the ES256 example of RFC 7515, Appendix A.3
+var rfc7515ES256 = struct {
+	x, y                       string
+	header, payload, signature string
+}{
+	x:         "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+	y:         "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+	header:    "eyJhbGciOiJFUzI1NiJ9",
+	payload:   "eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ",
+	signature: "DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q",
+}
+
+// TestECDSAVector verifies the RFC 7515 ES256 example token
+func TestECDSAVector(t *testing.T) {
+	x, _ := base64.RawURLEncoding.DecodeString(rfc7515ES256.x)
+	y, _ := base64.RawURLEncoding.DecodeString(rfc7515ES256.y)
+
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	token := rfc7515ES256.header + "." + rfc7515ES256.payload + "." + rfc7515ES256.signature
+
+	var claims Claims
+
+	// The example header carries no 'typ'
+	if err := Unmarshal(token, &claims, publicKey, WithAllowedTypes(""), WithNow(time.Unix(1300819300, 0))); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if claims.Issuer != "joe" {
+		t.Errorf("Issuer = %q, want %q", claims.Issuer, "joe")
+	}
+}
+
+// TestECDSARoundTrip verifies ES256, ES384, and ES512 tokens verify with the public key
+func TestECDSARoundTrip(t *testing.T) {
+	tests := []struct {
+		alg   string
+		curve elliptic.Curve
+		size  int
+	}{
+		{alg: ES256, curve: elliptic.P256(), size: 64},
+		{alg: ES384, curve: elliptic.P384(), size: 96},
+		{alg: ES512, curve: elliptic.P521(), size: 132},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			privateKey, _ := ecdsa.GenerateKey(tt.curve, rand.Reader)
+
+			token, err := Marshal(Header{Alg: tt.alg}, Claims{Subject: "user123"}, privateKey)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			b64vals := b64values{}
+			_ = b64vals.unmarshal(token)
+
+			if signature, _ := decodeJWTBase64(b64vals.signature); len(signature) != tt.size {
+				t.Errorf("signature length = %d, want %d", len(signature), tt.size)
+			}
+
+			var claims Claims
+
+			if err := Unmarshal(token, &claims, &privateKey.PublicKey); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if claims.Subject != "user123" {
+				t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+			}
+		})
+	}
+}
+
+// TestECDSARejection verifies malformed lengths, tampering, and mismatched keys fail
+func TestECDSARejection(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherCurve, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	token, err := Marshal(Header{Alg: ES256}, Claims{Subject: "user123"}, privateKey)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	b64vals := b64values{}
+	_ = b64vals.unmarshal(token)
+
+	signature, _ := decodeJWTBase64(b64vals.signature)
+
+	withSignature := func(signature []byte) string {
+		return b64vals.header + "." + b64vals.payload + "." + encodeJWTBase64(signature)
+	}
+
+	tampered := append([]byte(nil), signature...)
+	tampered[0] ^= 0xff
+
+	tests := []struct {
+		name    string
+		token   string
+		key     any
+		wantErr error
+	}{
+		{name: "tampered signature", token: withSignature(tampered), key: &privateKey.PublicKey, wantErr: ErrSignatureMismatch},
+		{name: "key on another curve", token: token, key: &otherCurve.PublicKey, wantErr: ErrInvalidKeyType},
+		{name: "HMAC secret", token: token, key: []byte("secret"), wantErr: ErrAlgorithmMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, tt.key); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("off-by-one signature lengths", func(t *testing.T) {
+		for _, tt := range []struct {
+			alg  string
+			size int
+		}{
+			{alg: ES256, size: 64},
+			{alg: ES384, size: 96},
+			{alg: ES512, size: 132},
+		} {
+			header := encodeJWTBase64([]byte(`{"alg":"` + tt.alg + `","typ":"JWT"}`))
+
+			for _, n := range []int{tt.size - 1, tt.size + 1} {
+				malformed := header + "." + b64vals.payload + "." + encodeJWTBase64(make([]byte, n))
+
+				if err := Unmarshal(malformed, &Claims{}, &privateKey.PublicKey); err != ErrInvalidToken {
+					t.Errorf("%s with %d signature bytes: Unmarshal() error = %v, want %v", tt.alg, n, err, ErrInvalidToken)
+				}
+			}
+		}
+	})
+
+	t.Run("signing with a key on another curve", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: ES256}, Claims{}, otherCurve); err != ErrInvalidKeyType {
+			t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidKeyType)
+		}
+	})
+}
+
+// TestECDSADERSignatures verifies DER signatures are accepted only when opted in, and only in canonical form
+func TestECDSADERSignatures(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	token, _ := Marshal(Header{Alg: ES256}, Claims{Subject: "user123"}, privateKey)
+
+	b64vals := b64values{}
+	_ = b64vals.unmarshal(token)
+
+	signature, _ := decodeJWTBase64(b64vals.signature)
+
+	withSignature := func(signature []byte) string {
+		return b64vals.header + "." + b64vals.payload + "." + encodeJWTBase64(signature)
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+
+	der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+
+	// R with a redundant leading zero byte decodes to the same value
+	rDER, _ := asn1.Marshal(r)
+	sDER, _ := asn1.Marshal(s)
+	body := append(append([]byte{0x02, rDER[1] + 1, 0x00}, rDER[2:]...), sDER...)
+	nonMinimal := append([]byte{0x30, byte(len(body))}, body...)
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherToken, _ := Marshal(Header{Alg: ES256}, Claims{Subject: "other"}, otherKey)
+	otherSignature, _ := decodeJWTBase64(strings.Split(otherToken, ".")[2])
+
+	otherDER, _ := asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).SetBytes(otherSignature[:32]),
+		new(big.Int).SetBytes(otherSignature[32:]),
+	})
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "R||S by default", token: token},
+		{name: "R||S with DER accepted", token: token, opts: []Option{WithAcceptDERSignatures()}},
+		{name: "DER rejected by default", token: withSignature(der), wantErr: ErrInvalidToken},
+		{name: "DER with DER accepted", token: withSignature(der), opts: []Option{WithAcceptDERSignatures()}},
+		{name: "DER of another message", token: withSignature(otherDER), opts: []Option{WithAcceptDERSignatures()}, wantErr: ErrSignatureMismatch},
+		{name: "trailing data after DER", token: withSignature(append(append([]byte(nil), der...), 0x00)), opts: []Option{WithAcceptDERSignatures()}, wantErr: ErrSignatureMismatch},
+		{name: "non-minimal DER integer", token: withSignature(nonMinimal), opts: []Option{WithAcceptDERSignatures()}, wantErr: ErrSignatureMismatch},
+		{name: "too short for DER", token: withSignature(der[:7]), opts: []Option{WithAcceptDERSignatures()}, wantErr: ErrInvalidToken},
+		{name: "too long for DER", token: withSignature(make([]byte, maxDERSignature(32)+1)), opts: []Option{WithAcceptDERSignatures()}, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, &privateKey.PublicKey, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("HMAC lengths are unaffected", func(t *testing.T) {
+		secret := []byte("test-secret")
+		hmacToken, _ := Marshal(Header{Alg: HS256}, Claims{}, secret)
+		truncated := hmacToken[:len(hmacToken)-4]
+
+		if err := Unmarshal(truncated, &Claims{}, secret, WithAcceptDERSignatures()); err != ErrSignatureMismatch {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrSignatureMismatch)
+		}
+	})
+}
+
+// TestEdDSA verifies Ed25519 tokens round-trip and match crypto/ed25519 directly
+func TestEdDSA(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, err := Marshal(Header{Alg: EdDSA}, Claims{Subject: "user123"}, privateKey)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		var claims Claims
+
+		if err := Unmarshal(token, &claims, publicKey); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if claims.Subject != "user123" {
+			t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+		}
+	})
+
+	t.Run("matches crypto/ed25519", func(t *testing.T) {
+		b64vals := b64values{}
+		_ = b64vals.unmarshal(token)
+
+		signingInput := []byte(b64vals.header + "." + b64vals.payload)
+		signature, _ := decodeJWTBase64(b64vals.signature)
+
+		if !ed25519.Verify(publicKey, signingInput, signature) {
+			t.Error("ed25519.Verify() = false for the Marshal signature")
+		}
+
+		direct := b64vals.header + "." + b64vals.payload + "." + encodeJWTBase64(ed25519.Sign(privateKey, signingInput))
+
+		if direct != token {
+			t.Errorf("Marshal() = %q, want the crypto/ed25519 signature %q", token, direct)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		key     any
+		wantErr error
+	}{
+		{name: "another public key", key: otherPublicKey, wantErr: ErrSignatureMismatch},
+		{name: "short public key", key: publicKey[:31], wantErr: ErrInvalidKeySize},
+		{name: "long public key", key: append(append(ed25519.PublicKey(nil), publicKey...), 0), wantErr: ErrInvalidKeySize},
+		{name: "private key", key: privateKey, wantErr: ErrInvalidKeyType},
+		{name: "HMAC secret", key: []byte("secret"), wantErr: ErrAlgorithmMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(token, &Claims{}, tt.key); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("short private key", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: EdDSA}, Claims{}, privateKey[:32]); err != ErrInvalidKeySize {
+			t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidKeySize)
+		}
+	})
+}
+
+// TestNoneAlgorithm verifies unsigned tokens require the UnsafeAllowNoneSignature opt-in
+func TestNoneAlgorithm(t *testing.T) {
+	secret := []byte("secret")
+
+	token, err := Marshal(Header{Alg: None}, Claims{Subject: "user123"}, UnsafeAllowNoneSignature)
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if !strings.HasSuffix(token, ".") {
+		t.Fatalf("Marshal() = %q, want an empty signature segment", token)
+	}
+
+	t.Run("accepted with the opt-in", func(t *testing.T) {
+		var claims Claims
+
+		if err := Unmarshal(token, &claims, UnsafeAllowNoneSignature); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if claims.Subject != "user123" {
+			t.Errorf("Subject = %q, want %q", claims.Subject, "user123")
+		}
+	})
+
+	t.Run("rejected by default", func(t *testing.T) {
+		for _, key := range []any{secret, nil, SymmetricKeySet{"k": secret}} {
+			if err := Unmarshal(token, &Claims{}, key); err != (unsupportedAlgorithmError{alg: None}) {
+				t.Errorf("Unmarshal() with %T error = %v, want %v", key, err, unsupportedAlgorithmError{alg: None})
+			}
+		}
+	})
+
+	t.Run("signature must be empty", func(t *testing.T) {
+		if err := Unmarshal(token+"c2ln", &Claims{}, UnsafeAllowNoneSignature); err != ErrInvalidToken {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+
+	t.Run("opt-in does not verify signed tokens", func(t *testing.T) {
+		signed, _ := Marshal(Header{Alg: HS256}, Claims{}, secret)
+
+		if err := Unmarshal(signed, &Claims{}, UnsafeAllowNoneSignature); err != ErrAlgorithmMismatch {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrAlgorithmMismatch)
+		}
+	})
+
+	t.Run("Marshal requires the opt-in", func(t *testing.T) {
+		if _, err := Marshal(Header{Alg: None}, Claims{}, secret); err != (unsupportedAlgorithmError{alg: None}) {
+			t.Errorf("Marshal() error = %v, want %v", err, unsupportedAlgorithmError{alg: None})
+		}
+	})
+}
+
+// TestAlgorithmConfusion verifies tokens naming an algorithm of another key family are rejected
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	t.Run("HS256 signed with the RSA public key", func(t *testing.T) {
+		// The attacker knows the public key and uses its encoding as the HMAC secret
+		der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+
+		forged, err := Marshal(Header{Alg: HS256}, Claims{Subject: "admin"}, encodePEM("PUBLIC KEY", der))
+
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		if err := Unmarshal(forged, &Claims{}, &rsaKey.PublicKey); err != ErrAlgorithmMismatch {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrAlgorithmMismatch)
+		}
+	})
+
+	rsaToken, _ := Marshal(Header{Alg: RS256}, Claims{}, rsaKey)
+	hmacToken, _ := Marshal(Header{Alg: HS256}, Claims{}, []byte("secret"))
+
+	tests := []struct {
+		name  string
+		token string
+		key   any
+	}{
+		{name: "HS256 with an ECDSA public key", token: hmacToken, key: &ecKey.PublicKey},
+		{name: "HS256 with an Ed25519 public key", token: hmacToken, key: edPublicKey},
+		{name: "RS256 with an ECDSA public key", token: rsaToken, key: &ecKey.PublicKey},
+		{name: "RS256 with a secret", token: rsaToken, key: []byte("secret")},
+		{name: "RS256 from an HMAC key set", token: rsaToken, key: SymmetricKeySet{"": []byte("secret")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.token, &Claims{}, tt.key); err != ErrAlgorithmMismatch {
+				t.Errorf("Unmarshal() error = %v, want %v", err, ErrAlgorithmMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import "encoding/json"
+
+// registeredClaimNames lists the JSON names of the fields of Claims
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// StandardClaims extracts the registered claims from a decoded claims value of
+// any type, such as a struct embedding Claims, a struct with jwt tags, or a
+// map, so that generic middleware can log them. It reports false when no
+// registered claim can be found.
+func StandardClaims(target any) (Claims, bool) {
+	var c Claims
+	found := false
+
+	withRegisteredClaims(target, func(registered *Claims) {
+		c = *registered
+		found = true
+	})
+
+	if found {
+		return c, true
+	}
+
+	if c, ok := taggedClaims(target, newOptions(nil)); ok {
+		return c, true
+	}
+
+	// Anything else is inspected through its JSON encoding
+	encoded, err := json.Marshal(target)
+
+	if err != nil {
+		return Claims{}, false
+	}
+
+	var fields map[string]json.RawMessage
+
+	if json.Unmarshal(encoded, &fields) != nil {
+		return Claims{}, false
+	}
+
+	for _, name := range registeredClaimNames {
+		if _, ok := fields[name]; ok {
+			found = true
+		}
+	}
+
+	if !found || json.Unmarshal(encoded, &c) != nil {
+		return Claims{}, false
+	}
+
+	return c, true
+}
@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestStandardClaims verifies registered claims are found in any decoded target
+func TestStandardClaims(t *testing.T) {
+	exp := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	type Embedded struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	type EmbeddedPointer struct {
+		*Claims
+		Role string `json:"role"`
+	}
+
+	type Tagged struct {
+		User    string    `json:"user" jwt:"sub"`
+		Expires time.Time `json:"expires" jwt:"exp"`
+	}
+
+	type Plain struct {
+		Subject string `json:"sub"`
+		Tenant  string `json:"tenant"`
+	}
+
+	type Unrelated struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name   string
+		target any
+		want   Claims
+		wantOK bool
+	}{
+		{
+			name:   "claims",
+			target: &Claims{Subject: "user123"},
+			want:   Claims{Subject: "user123"},
+			wantOK: true,
+		},
+		{
+			name:   "embedded claims",
+			target: &Embedded{Claims: Claims{Subject: "user123", ExpiresAt: exp.Unix()}, Role: "admin"},
+			want:   Claims{Subject: "user123", ExpiresAt: exp.Unix()},
+			wantOK: true,
+		},
+		{
+			name:   "embedded claims pointer",
+			target: EmbeddedPointer{Claims: &Claims{Issuer: "issuer"}},
+			want:   Claims{Issuer: "issuer"},
+			wantOK: true,
+		},
+		{
+			name:   "tagged struct",
+			target: &Tagged{User: "user123", Expires: exp},
+			want:   Claims{Subject: "user123", ExpiresAt: exp.Unix()},
+			wantOK: true,
+		},
+		{
+			name:   "plain struct",
+			target: &Plain{Subject: "user123", Tenant: "acme"},
+			want:   Claims{Subject: "user123"},
+			wantOK: true,
+		},
+		{
+			name:   "map",
+			target: &map[string]any{"sub": "user123", "aud": []any{"a", "b"}, "exp": float64(exp.Unix())},
+			want:   Claims{Subject: "user123", Audience: Audience{"a", "b"}, ExpiresAt: exp.Unix()},
+			wantOK: true,
+		},
+		{
+			name:   "map without registered claims",
+			target: map[string]any{"role": "admin"},
+			wantOK: false,
+		},
+		{
+			name:   "unrelated struct",
+			target: &Unrelated{Name: "x"},
+			wantOK: false,
+		},
+		{
+			name:   "malformed audience",
+			target: map[string]any{"aud": 123},
+			wantOK: false,
+		},
+		{
+			name:   "nil",
+			target: nil,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StandardClaims(tt.target)
+
+			if ok != tt.wantOK {
+				t.Fatalf("StandardClaims() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StandardClaims() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String formats the header as space-separated key=value pairs, e.g.
+// "alg=HS256 typ=JWT kid=abc", omitting an empty key ID.
+func (h Header) String() string {
+	var b strings.Builder
+
+	writeField(&b, "alg", h.Alg)
+	writeField(&b, "typ", h.Typ)
+
+	if h.Kid != "" {
+		writeField(&b, "kid", h.Kid)
+	}
+
+	return b.String()
+}
+
+// String summarizes the registered claims as space-separated key=value pairs,
+// omitting unset claims and formatting times in RFC 3339. Types embedding
+// Claims inherit it, so their custom claims are left out of log output.
+func (c Claims) String() string {
+	var b strings.Builder
+
+	if c.Issuer != "" {
+		writeField(&b, "iss", c.Issuer)
+	}
+
+	if c.Subject != "" {
+		writeField(&b, "sub", c.Subject)
+	}
+
+	if len(c.Audience) > 0 {
+		writeField(&b, "aud", strings.Join(c.Audience, ","))
+	}
+
+	if c.ExpiresAt != 0 {
+		writeField(&b, "exp", formatNumericDate(c.ExpiresAt))
+	}
+
+	if c.NotBefore != 0 {
+		writeField(&b, "nbf", formatNumericDate(c.NotBefore))
+	}
+
+	if c.IssuedAt != 0 {
+		writeField(&b, "iat", formatNumericDate(c.IssuedAt))
+	}
+
+	if c.ID != "" {
+		writeField(&b, "jti", c.ID)
+	}
+
+	return b.String()
+}
+
+// writeField appends key=value to b, quoting values that are empty or hold
+// spaces, quotes, or non-printable characters.
+func writeField(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	if value == "" || strings.ContainsAny(value, " \"=") || strconv.Quote(value) != `"`+value+`"` {
+		b.WriteString(strconv.Quote(value))
+		return
+	}
+
+	b.WriteString(value)
+}
+
+func formatNumericDate(sec int64) string {
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestHeaderString verifies headers format as key=value pairs
+func TestHeaderString(t *testing.T) {
+	tests := []struct {
+		name   string
+		header Header
+		want   string
+	}{
+		{
+			name:   "without kid",
+			header: Header{Alg: HS256, Typ: JWT},
+			want:   "alg=HS256 typ=JWT",
+		},
+		{
+			name:   "with kid",
+			header: Header{Alg: HS256, Typ: JWT, Kid: "abc"},
+			want:   "alg=HS256 typ=JWT kid=abc",
+		},
+		{
+			name:   "empty typ is quoted",
+			header: Header{Alg: HS512},
+			want:   `alg=HS512 typ=""`,
+		},
+		{
+			name:   "spaces are quoted",
+			header: Header{Alg: HS256, Typ: JWT, Kid: "a b"},
+			want:   `alg=HS256 typ=JWT kid="a b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.header.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClaimsString verifies claims summarize only the set registered claims
+func TestClaimsString(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims Claims
+		want   string
+	}{
+		{
+			name:   "empty claims",
+			claims: Claims{},
+			want:   "",
+		},
+		{
+			name: "all claims",
+			claims: Claims{
+				Issuer:    "issuer",
+				Subject:   "user123",
+				Audience:  Audience{"web", "api"},
+				ExpiresAt: 1700003600,
+				NotBefore: 1700000000,
+				IssuedAt:  1700000000,
+				ID:        "id-1",
+			},
+			want: "iss=issuer sub=user123 aud=web,api exp=2023-11-14T23:13:20Z nbf=2023-11-14T22:13:20Z iat=2023-11-14T22:13:20Z jti=id-1",
+		},
+		{
+			name:   "newline is quoted",
+			claims: Claims{Subject: "a\nb"},
+			want:   `sub="a\nb"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("embedding types omit custom claims", func(t *testing.T) {
+		type CustomClaims struct {
+			Claims
+			Extra map[string]any `json:"extra"`
+		}
+
+		claims := CustomClaims{
+			Claims: Claims{Subject: "user123"},
+			Extra:  map[string]any{"large": "value"},
+		}
+
+		if got := fmt.Sprint(&claims); got != "sub=user123" {
+			t.Errorf("Sprint() = %q, want %q", got, "sub=user123")
+		}
+	})
+}
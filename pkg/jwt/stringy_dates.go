@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// numericDateClaims are the registered claims holding a NumericDate
+var numericDateClaims = []string{"exp", "nbf", "iat"}
+
+// unquoteNumericDates rewrites the time claims of a JSON payload that are
+// encoded as strings into numbers, so that they decode like compliant
+// claims. A time claim string that is not an integer yields
+// ErrMalformedClaims. Payloads that are not JSON objects are returned as-is,
+// leaving the error to the regular decoding.
+func unquoteNumericDates(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data, nil
+	}
+
+	changed := false
+
+	for _, name := range numericDateClaims {
+		raw := fields[name]
+
+		if len(raw) == 0 || raw[0] != '"' {
+			continue
+		}
+
+		var s string
+
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrMalformedClaims
+		}
+
+		n, err := strconv.ParseInt(s, 10, 64)
+
+		if err != nil {
+			return nil, ErrMalformedClaims
+		}
+
+		fields[name] = json.RawMessage(strconv.FormatInt(n, 10))
+		changed = true
+	}
+
+	if !changed {
+		return data, nil
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestWithStringyDates verifies string-encoded time claims are accepted only when enabled
+func TestWithStringyDates(t *testing.T) {
+	secret := []byte("test-secret")
+	now := WithNow(time.Unix(1700000000, 0))
+
+	sign := func(payload string) string {
+		header := encodeJWTBase64([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		encoded := encodeJWTBase64([]byte(payload))
+		signature, _ := ComputeSignature(header+"."+encoded, HS256, secret)
+		return header + "." + encoded + "." + signature
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		opts    []Option
+		want    Claims
+		wantErr error
+		anyErr  bool
+	}{
+		{
+			name:    "string dates",
+			payload: `{"sub":"user123","exp":"1700003600","nbf":"1699999000","iat":"1699999000"}`,
+			opts:    []Option{now, WithStringyDates()},
+			want:    Claims{Subject: "user123", ExpiresAt: 1700003600, NotBefore: 1699999000, IssuedAt: 1699999000},
+		},
+		{
+			name:    "numeric dates still accepted",
+			payload: `{"exp":1700003600}`,
+			opts:    []Option{now, WithStringyDates()},
+			want:    Claims{ExpiresAt: 1700003600},
+		},
+		{
+			name:    "string dates still validated",
+			payload: `{"exp":"1699999999"}`,
+			opts:    []Option{now, WithStringyDates()},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "non-integer string",
+			payload: `{"exp":"soon"}`,
+			opts:    []Option{now, WithStringyDates()},
+			wantErr: ErrMalformedClaims,
+		},
+		{
+			name:    "fractional string",
+			payload: `{"exp":"1700003600.5"}`,
+			opts:    []Option{now, WithStringyDates()},
+			wantErr: ErrMalformedClaims,
+		},
+		{
+			name:    "string dates rejected by default",
+			payload: `{"exp":"1700003600"}`,
+			opts:    []Option{now},
+			anyErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Claims
+
+			err := Unmarshal(sign(tt.payload), &got, secret, tt.opts...)
+
+			if tt.anyErr {
+				if err == nil {
+					t.Fatal("Unmarshal() error = nil, want an error")
+				}
+
+				return
+			}
+
+			if err != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() claims = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("map claims see numbers", func(t *testing.T) {
+		var got map[string]any
+
+		if err := Unmarshal(sign(`{"exp":"1700003600"}`), &got, secret, now, WithStringyDates()); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if got["exp"] != float64(1700003600) {
+			t.Errorf("exp = %#v, want a number", got["exp"])
+		}
+	})
+}
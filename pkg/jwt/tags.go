@@ -0,0 +1,141 @@
+package jwt
+
+import (
+	"reflect"
+	"time"
+)
+
+// taggedClaims builds registered claims from the fields of a struct tagged
+// with `jwt:"<claim>"`, such as `jwt:"exp"`, so that types which do not embed
+// Claims can still be validated. It reports false when no field is tagged.
+// Time claims may be integer, float, or time.Time fields; the latter are
+// converted with o.numericDate.
+func taggedClaims(v any, o *options) (Claims, bool) {
+	rv, ok := taggedStruct(v)
+
+	if !ok {
+		return Claims{}, false
+	}
+
+	c := Claims{}
+	found := false
+
+	for i := 0; i < rv.NumField(); i++ {
+		name, ok := rv.Type().Field(i).Tag.Lookup("jwt")
+
+		if !ok {
+			continue
+		}
+
+		if setTaggedClaim(&c, name, rv.Field(i), o) {
+			found = true
+		}
+	}
+
+	return c, found
+}
+
+// hasTaggedClaim reports whether v is a struct with a field tagged
+// `jwt:"<name>"`.
+func hasTaggedClaim(v any, name string) bool {
+	rv, ok := taggedStruct(v)
+
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		if tag, ok := rv.Type().Field(i).Tag.Lookup("jwt"); ok && tag == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// taggedStruct returns the struct v holds or points to.
+func taggedStruct(v any) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, false
+		}
+
+		rv = rv.Elem()
+	}
+
+	return rv, rv.Kind() == reflect.Struct
+}
+
+func setTaggedClaim(c *Claims, name string, field reflect.Value, o *options) bool {
+	switch name {
+	case "iss":
+		return setString(&c.Issuer, field)
+	case "sub":
+		return setString(&c.Subject, field)
+	case "jti":
+		return setString(&c.ID, field)
+	case "aud":
+		return setAudience(&c.Audience, field)
+	case "exp":
+		return setNumericDate(&c.ExpiresAt, field, o)
+	case "nbf":
+		return setNumericDate(&c.NotBefore, field, o)
+	case "iat":
+		return setNumericDate(&c.IssuedAt, field, o)
+	}
+
+	return false
+}
+
+func setString(dst *string, field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+
+	*dst = field.String()
+	return true
+}
+
+func setAudience(dst *Audience, field reflect.Value) bool {
+	switch {
+	case field.Kind() == reflect.String:
+		if s := field.String(); s != "" {
+			*dst = Audience{s}
+		}
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < field.Len(); i++ {
+			*dst = append(*dst, field.Index(i).String())
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func setNumericDate(dst *int64, field reflect.Value, o *options) bool {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		*dst = field.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		*dst = int64(field.Uint()) // #nosec G115 -- NumericDate values fit in int64
+	case reflect.Float32, reflect.Float64:
+		*dst = int64(field.Float())
+	case reflect.Struct:
+		if field.Type() != timeType {
+			return false
+		}
+
+		if t := field.Interface().(time.Time); !t.IsZero() {
+			*dst = o.numericDate(t)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTaggedClaims verifies jwt struct tags map fields to registered claims
+func TestTaggedClaims(t *testing.T) {
+	expires := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	type session struct {
+		User      string    `json:"user" jwt:"sub"`
+		Issuer    string    `json:"issuer" jwt:"iss"`
+		Audiences []string  `json:"audiences" jwt:"aud"`
+		Expiry    time.Time `json:"expiry" jwt:"exp"`
+		Started   int64     `json:"started" jwt:"iat"`
+		Untagged  string    `json:"untagged"`
+	}
+
+	s := session{
+		User:      "user123",
+		Issuer:    "issuer",
+		Audiences: []string{"api", "web"},
+		Expiry:    expires,
+		Started:   expires.Add(-time.Hour).Unix(),
+	}
+
+	c, ok := taggedClaims(&s, newOptions(nil))
+
+	if !ok {
+		t.Fatal("taggedClaims() ok = false, want true")
+	}
+
+	if c.Subject != "user123" || c.Issuer != "issuer" || len(c.Audience) != 2 {
+		t.Errorf("taggedClaims() = %+v", c)
+	}
+
+	if c.ExpiresAt != expires.Unix() || c.IssuedAt != s.Started {
+		t.Errorf("time claims = exp %v iat %v", c.ExpiresAt, c.IssuedAt)
+	}
+
+	t.Run("untagged struct", func(t *testing.T) {
+		type plain struct {
+			Exp int64 `json:"exp"`
+		}
+
+		if _, ok := taggedClaims(&plain{Exp: 1}, newOptions(nil)); ok {
+			t.Error("taggedClaims() ok = true, want false")
+		}
+	})
+
+	t.Run("non-struct values", func(t *testing.T) {
+		for _, v := range []any{nil, map[string]any{"exp": 1}, (*session)(nil), "string"} {
+			if _, ok := taggedClaims(v, newOptions(nil)); ok {
+				t.Errorf("taggedClaims(%T) ok = true, want false", v)
+			}
+		}
+	})
+}
+
+// TestUnmarshalValidatesTaggedClaims verifies tagged structs are time-validated
+func TestUnmarshalValidatesTaggedClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256}
+
+	type custom struct {
+		Expiry int64  `json:"expiry" jwt:"exp"`
+		Role   string `json:"role"`
+	}
+
+	t.Run("expired tagged field", func(t *testing.T) {
+		token, _ := Marshal(header, custom{Expiry: time.Now().Add(-time.Hour).Unix(), Role: "admin"}, secret)
+
+		var decoded custom
+
+		if err := Unmarshal(token, &decoded, secret); err != ErrTokenExpired {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+		}
+	})
+
+	t.Run("valid tagged field", func(t *testing.T) {
+		token, _ := Marshal(header, custom{Expiry: time.Now().Add(time.Hour).Unix(), Role: "admin"}, secret)
+
+		var decoded custom
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.Role != "admin" {
+			t.Errorf("Role = %v, want admin", decoded.Role)
+		}
+	})
+
+	t.Run("json exp without tag is not validated", func(t *testing.T) {
+		type untagged struct {
+			Exp int64 `json:"exp"`
+		}
+
+		token, _ := Marshal(header, untagged{Exp: time.Now().Add(-time.Hour).Unix()}, secret)
+
+		var decoded untagged
+
+		if err := Unmarshal(token, &decoded, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package jwt
+
+import "time"
+
+// TestVectorEpoch is the Unix time at which GenerateTestVector signs tokens,
+// 2024-01-01T00:00:00Z.
+const TestVectorEpoch = 1704067200
+
+// GenerateTestVector returns the token Marshal produces for header, claims,
+// and secret with the clock fixed at TestVectorEpoch, for regenerating the
+// exact tokens pinned by golden tests. HMAC signatures and the JSON encoding
+// of claims are deterministic, so the same arguments always yield the same
+// token, including the 'iat' and 'nbf' claims set by WithAutoIssuedAt and
+// WithNotBefore; a changed vector therefore means the encoding changed. The
+// clock cannot be overridden through opts.
+//
+// Verify such tokens with WithNow(time.Unix(TestVectorEpoch, 0)) so that
+// time-based validation is as reproducible as the token itself.
+func GenerateTestVector(header Header, claims any, secret []byte, opts ...Option) (string, error) {
+	fixed := append(append([]Option(nil), opts...), WithNow(time.Unix(TestVectorEpoch, 0)))
+
+	return Marshal(header, claims, secret, fixed...)
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestGenerateTestVector verifies vectors are reproducible and match the pinned golden token
+func TestGenerateTestVector(t *testing.T) {
+	secret := []byte("test-vector-secret")
+	claims := Claims{Issuer: "gotoken", Subject: "user123", ExpiresAt: TestVectorEpoch + 3600}
+
+	// Regenerate with GenerateTestVector and the arguments below when the
+	// claims encoding changes on purpose, after checking the new payload
+	const golden = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		"eyJpc3MiOiJnb3Rva2VuIiwic3ViIjoidXNlcjEyMyIsImV4cCI6MTcwNDA3MDgwMCwiaWF0IjoxNzA0MDY3MjAwfQ." +
+		"yCKor6E8_DpGfPJr-i5Mp-39jJofRMwq3vZ_kJtyyi8"
+
+	token, err := GenerateTestVector(Header{Alg: HS256}, claims, secret, WithAutoIssuedAt())
+
+	if err != nil {
+		t.Fatalf("GenerateTestVector() error = %v", err)
+	}
+
+	if token != golden {
+		t.Errorf("GenerateTestVector() = %q, want %q", token, golden)
+	}
+
+	again, _ := GenerateTestVector(Header{Alg: HS256}, claims, secret, WithAutoIssuedAt())
+
+	if again != token {
+		t.Errorf("GenerateTestVector() = %q on the second call, want %q", again, token)
+	}
+
+	t.Run("clock cannot be overridden", func(t *testing.T) {
+		overridden, _ := GenerateTestVector(Header{Alg: HS256}, claims, secret, WithNow(time.Now()), WithAutoIssuedAt())
+
+		if overridden != golden {
+			t.Errorf("GenerateTestVector() = %q, want %q", overridden, golden)
+		}
+	})
+
+	t.Run("verifies at the fixed clock", func(t *testing.T) {
+		var decoded Claims
+
+		if err := Unmarshal(token, &decoded, secret, WithNow(time.Unix(TestVectorEpoch, 0))); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if decoded.IssuedAt != TestVectorEpoch {
+			t.Errorf("IssuedAt = %d, want %d", decoded.IssuedAt, TestVectorEpoch)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import "encoding/json"
+
+// Token is a parsed but not yet verified JWT, exposing its segments for
+// callers that verify and decode in separate steps. Nothing in a Token can be
+// trusted before Verify returns nil.
+type Token struct {
+	// Header is the decoded header
+	Header Header
+
+	// Claims is the decoded payload JSON
+	Claims json.RawMessage
+
+	// Signature is the decoded signature
+	Signature []byte
+
+	// Raw is the compact token it was parsed from
+	Raw string
+}
+
+// ParseToken splits and decodes jws into a Token without verifying it. Tokens
+// whose segments are not base64url, whose header is not a valid JSON object,
+// or whose payload is not a JSON object yield an error; ErrInvalidToken
+// unless a more specific error applies.
+func ParseToken(jws string) (*Token, error) {
+	b64vals := b64values{}
+
+	if err := b64vals.unmarshal(jws); err != nil {
+		return nil, err
+	}
+
+	if b64vals.header == "" || b64vals.payload == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if !isBase64URL(b64vals.header) || !isBase64URL(b64vals.payload) || !isBase64URL(b64vals.signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var header Header
+
+	if err := header.unmarshal(b64vals.header); err != nil {
+		return nil, err
+	}
+
+	if !isJSONObject(b64vals.payload) {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := decodeJWTBase64(b64vals.payload)
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := decodeJWTBase64(b64vals.signature)
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Token{
+		Header:    header,
+		Claims:    claims,
+		Signature: signature,
+		Raw:       jws,
+	}, nil
+}
+
+// Verify verifies the signature of the token and validates it exactly like
+// Unmarshal with the same key and options, decoding the claims into Claims.
+// The fields of t are not consulted, so modifying them has no effect.
+func (t *Token) Verify(key any, opts ...Option) error {
+	return Unmarshal(t.Raw, &Claims{}, key, opts...)
+}
+
+// DecodeClaims decodes the claims into target, which must be a non-nil
+// pointer. It neither verifies nor validates them: call Verify first unless
+// the claims are only inspected, e.g. for logging.
+func (t *Token) DecodeClaims(target any) error {
+	if isNilPointer(target) {
+		return ErrNilClaimsTarget
+	}
+
+	return json.Unmarshal(t.Claims, target)
+}
@@ -0,0 +1,113 @@
+package jwt
+
+import "testing"
+
+// TestParseToken verifies the segments are decoded without verification
+func TestParseToken(t *testing.T) {
+	secret := []byte("test-secret")
+
+	jws, _ := Marshal(Header{Alg: HS384, Kid: "v1"}, Claims{Subject: "user123"}, secret)
+
+	tok, err := ParseToken(jws)
+
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+
+	if tok.Header != (Header{Alg: HS384, Typ: JWT, Kid: "v1"}) {
+		t.Errorf("Header = %+v", tok.Header)
+	}
+
+	if string(tok.Claims) != `{"sub":"user123"}` {
+		t.Errorf("Claims = %s", tok.Claims)
+	}
+
+	if len(tok.Signature) != 48 {
+		t.Errorf("len(Signature) = %d, want 48", len(tok.Signature))
+	}
+
+	if tok.Raw != jws {
+		t.Errorf("Raw = %q, want %q", tok.Raw, jws)
+	}
+
+	tests := []struct {
+		name    string
+		jws     string
+		wantErr error
+	}{
+		{name: "two segments", jws: "a.b", wantErr: ErrInvalidToken},
+		{name: "empty payload", jws: encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "..sig", wantErr: ErrInvalidToken},
+		{name: "header not an object", jws: encodeJWTBase64([]byte(`[]`)) + "." + encodeJWTBase64([]byte(`{}`)) + ".sig", wantErr: ErrMalformedHeader},
+		{name: "payload not an object", jws: encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "." + encodeJWTBase64([]byte(`"sub"`)) + ".sig", wantErr: ErrInvalidToken},
+		{name: "signature not base64url", jws: encodeJWTBase64([]byte(`{"alg":"HS256"}`)) + "." + encodeJWTBase64([]byte(`{}`)) + ".s+g", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseToken(tt.jws); err != tt.wantErr {
+				t.Errorf("ParseToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTokenVerify verifies Verify behaves like Unmarshal
+func TestTokenVerify(t *testing.T) {
+	secret := []byte("test-secret")
+
+	valid, _ := Marshal(Header{Alg: HS256}, Claims{Issuer: "issuer"}, secret)
+	expired, _ := Marshal(Header{Alg: HS256}, Claims{ExpiresAt: 1}, secret)
+
+	tests := []struct {
+		name    string
+		jws     string
+		key     []byte
+		opts    []Option
+		wantErr error
+	}{
+		{name: "valid token", jws: valid, key: secret},
+		{name: "wrong secret", jws: valid, key: []byte("other"), wantErr: ErrSignatureMismatch},
+		{name: "expired token", jws: expired, key: secret, wantErr: ErrTokenExpired},
+		{name: "options apply", jws: valid, key: secret, opts: []Option{WithIssuer("other")}, wantErr: ErrInvalidIssuer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := ParseToken(tt.jws)
+
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+
+			if err := tok.Verify(tt.key, tt.opts...); err != tt.wantErr {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTokenDecodeClaims verifies claims decode into the caller's target
+func TestTokenDecodeClaims(t *testing.T) {
+	jws, _ := Marshal(Header{Alg: HS256}, map[string]any{"sub": "user123", "role": "admin"}, []byte("secret"))
+
+	tok, _ := ParseToken(jws)
+
+	var claims struct {
+		Claims
+		Role string `json:"role"`
+	}
+
+	if err := tok.DecodeClaims(&claims); err != nil {
+		t.Fatalf("DecodeClaims() error = %v", err)
+	}
+
+	if claims.Subject != "user123" || claims.Role != "admin" {
+		t.Errorf("DecodeClaims() = %+v", claims)
+	}
+
+	var nilClaims *Claims
+
+	if err := tok.DecodeClaims(nilClaims); err != ErrNilClaimsTarget {
+		t.Errorf("DecodeClaims() error = %v, want %v", err, ErrNilClaimsTarget)
+	}
+}
@@ -0,0 +1,34 @@
+package jwt
+
+import (
+	"net/url"
+	"strings"
+)
+
+// FromURLParam extracts a compact JWT from a URL query parameter value. It
+// percent-decodes value, trims surrounding whitespace, and drops anything from
+// the first '#' or '&', which frameworks sometimes leave behind. The result
+// must then pass Validate, otherwise ErrInvalidToken is returned rather than a
+// guess at the intended token. Clean tokens pass through unchanged.
+//
+// FromURLParam only normalizes transport noise; the token must still be
+// verified with Unmarshal.
+func FromURLParam(value string) (string, error) {
+	unescaped, err := url.QueryUnescape(value)
+
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	jws := strings.TrimSpace(unescaped)
+
+	if i := strings.IndexAny(jws, "#&"); i >= 0 {
+		jws = jws[:i]
+	}
+
+	if err := Validate(jws); err != nil {
+		return "", err
+	}
+
+	return jws, nil
+}
@@ -0,0 +1,60 @@
+package jwt
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestFromURLParam verifies transport noise is removed and ambiguous input rejected
+func TestFromURLParam(t *testing.T) {
+	secret := []byte("test-secret")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: "user123"}, secret)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "clean token", value: token, want: token},
+		{name: "percent-encoded", value: url.QueryEscape(token), want: token},
+		{name: "encoded dots", value: strings.ReplaceAll(token, ".", "%2E"), want: token},
+		{name: "trailing fragment", value: token + "#state", want: token},
+		{name: "trailing parameter", value: token + "&next=/home", want: token},
+		{name: "encoded trailing parameter", value: token + "%26next%3D%2Fhome", want: token},
+		{name: "surrounding whitespace", value: " " + token + "\n", want: token},
+		{name: "invalid escape", value: token + "%zz", wantErr: ErrInvalidToken},
+		{name: "garbage without delimiter", value: token + "garbage", wantErr: ErrInvalidToken},
+		{name: "embedded space", value: token + " other", wantErr: ErrInvalidToken},
+		{name: "leading delimiter", value: "#" + token, wantErr: ErrInvalidToken},
+		{name: "empty value", value: "", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromURLParam(tt.value)
+
+			if err != tt.wantErr {
+				t.Fatalf("FromURLParam() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("FromURLParam() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("result verifies", func(t *testing.T) {
+		clean, err := FromURLParam(url.QueryEscape(token) + "#")
+
+		if err != nil {
+			t.Fatalf("FromURLParam() error = %v", err)
+		}
+
+		if err := Unmarshal(clean, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,515 @@
+package jwt
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// validator checks a token whose signature has already been verified.
+type validator func(t *token) error
+
+// registeredCheck adapts a check of the registered claims into a validator.
+func registeredCheck(check func(c *Claims) error) validator {
+	return func(t *token) error {
+		claims, err := t.payload.registeredClaims()
+
+		if err != nil {
+			return err
+		}
+
+		return check(claims)
+	}
+}
+
+// validate runs the configured validators in order, after the audience cap so
+// that oversized audiences never reach audience matching.
+func (o *options) validate(t *token) error {
+	if err := o.checkAudienceCount(t); err != nil {
+		return err
+	}
+
+	for _, v := range o.validators {
+		if err := v(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *options) checkAudienceCount(t *token) error {
+	if o.maxAudiences <= 0 {
+		return nil
+	}
+
+	claims, err := t.payload.registeredClaims()
+
+	if err != nil {
+		return err
+	}
+
+	if len(claims.Audience) > o.maxAudiences {
+		return ErrMalformedClaims
+	}
+
+	return nil
+}
+
+// WithIssuer makes Unmarshal reject tokens whose 'iss' claim differs from iss
+// with ErrInvalidIssuer. The comparison is exact unless WithIssuerNormalization
+// is given.
+func WithIssuer(iss string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			if o.normalizeIssuer(c.Issuer) != o.normalizeIssuer(iss) {
+				return ErrInvalidIssuer
+			}
+
+			return nil
+		}))
+	}
+}
+
+// WithAudience makes Unmarshal reject tokens whose 'aud' claim contains none
+// of the given audiences with ErrInvalidAudience. The comparison is exact
+// unless WithAudienceNormalization is given. Tokens without 'aud' are
+// rejected too, unless WithAudienceOptional is given.
+func WithAudience(aud ...string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			if c.Audience == nil && o.audienceOptional {
+				return nil
+			}
+
+			for _, actual := range c.Audience {
+				for _, expected := range aud {
+					if o.normalizeAudience(actual) == o.normalizeAudience(expected) {
+						return nil
+					}
+				}
+			}
+
+			return ErrInvalidAudience
+		}))
+	}
+}
+
+// WithIssuerNormalization applies fn to both the expected and the actual
+// issuer before WithIssuer compares them, e.g. to ignore a trailing slash.
+func WithIssuerNormalization(fn func(string) string) Option {
+	return func(o *options) {
+		o.issuerNormalization = fn
+	}
+}
+
+// WithAudienceOptional makes WithAudience accept tokens that have no 'aud'
+// claim at all, while still requiring a match when the claim is present, e.g.
+// to roll out audience validation gradually. An empty array counts as present.
+func WithAudienceOptional() Option {
+	return func(o *options) {
+		o.audienceOptional = true
+	}
+}
+
+// WithMaxAudiences makes Unmarshal reject tokens whose 'aud' claim holds more
+// than n audiences with ErrMalformedClaims, before any audience validator
+// runs. The number of audiences is unlimited by default.
+func WithMaxAudiences(n int) Option {
+	return func(o *options) {
+		o.maxAudiences = n
+	}
+}
+
+// WithAudienceNormalization applies fn to both the expected and the actual
+// audiences before WithAudience compares them.
+func WithAudienceNormalization(fn func(string) string) Option {
+	return func(o *options) {
+		o.audienceNormalization = fn
+	}
+}
+
+// NormalizeURL is a normalization function for WithIssuerNormalization and
+// WithAudienceNormalization that lowercases a value and trims trailing slashes.
+func NormalizeURL(s string) string {
+	return strings.TrimRight(strings.ToLower(s), "/")
+}
+
+func (o *options) normalizeIssuer(iss string) string {
+	if o.issuerNormalization == nil {
+		return iss
+	}
+
+	return o.issuerNormalization(iss)
+}
+
+func (o *options) normalizeAudience(aud string) string {
+	if o.audienceNormalization == nil {
+		return aud
+	}
+
+	return o.audienceNormalization(aud)
+}
+
+// WithIssuedAtWindow makes Unmarshal reject tokens whose 'iat' claim falls
+// outside [now-past, now+future] with ErrIssuedAtOutOfWindow. It replaces the
+// default rule rejecting any 'iat' after now. Tokens without 'iat' pass.
+func WithIssuedAtWindow(past, future time.Duration) Option {
+	return func(o *options) {
+		o.issuedAtWindow = true
+		o.validators = append(o.validators, registeredCheck(func(claims *Claims) error {
+			if claims.IssuedAt == 0 {
+				return nil
+			}
+
+			now := o.now()
+
+			if claims.IssuedAt < o.numericDate(now.Add(-past)) || claims.IssuedAt > o.numericDate(now.Add(future)) {
+				return ErrIssuedAtOutOfWindow
+			}
+
+			return nil
+		}))
+	}
+}
+
+// WithMaxNotBeforeFuture makes Unmarshal reject tokens whose 'nbf' claim lies
+// more than d after now with ErrNotBeforeTooFarAhead instead of
+// ErrTokenNotValidYet, since such a token is more likely an issuer bug than
+// early. WithLeeway extends the bound like the regular 'nbf' check, so with
+// leeway l a token is not yet valid while 'nbf' is within (now+l, now+l+d] and
+// rejected beyond. Tokens without 'nbf' pass.
+func WithMaxNotBeforeFuture(d time.Duration) Option {
+	return func(o *options) {
+		o.limitNotBefore = true
+		o.maxNotBefore = d
+	}
+}
+
+// WithMaxTTL makes Unmarshal reject tokens whose lifetime, 'exp' minus 'iat',
+// exceeds max with ErrTokenTTLExceeded. Unlike time-based checks it does not
+// depend on when the token is verified. Tokens lacking either claim pass; add
+// WithRequiredClaims("exp", "iat") to reject them instead.
+func WithMaxTTL(max time.Duration) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			if c.ExpiresAt == 0 || c.IssuedAt == 0 {
+				return nil
+			}
+
+			if o.fromNumericDate(c.ExpiresAt).Sub(o.fromNumericDate(c.IssuedAt)) > max {
+				return ErrTokenTTLExceeded
+			}
+
+			return nil
+		}))
+	}
+}
+
+// WithFreshness makes Unmarshal reject tokens issued more than d ago, or
+// lacking the 'iat' claim, with ErrTokenNotFresh, e.g. 30 seconds for a
+// password change that must follow a recent sign-in. The error differs from
+// ErrTokenExpired so callers can ask for re-authentication specifically.
+func WithFreshness(d time.Duration) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			if c.IssuedAt == 0 || o.now().Sub(o.fromNumericDate(c.IssuedAt)) > d {
+				return ErrTokenNotFresh
+			}
+
+			return nil
+		}))
+	}
+}
+
+// WithAudienceValidator makes Unmarshal pass the token audience, normalized to
+// a slice, to fn after the signature is verified. A non-nil error from fn is
+// returned verbatim. Without this option the audience is not checked.
+func WithAudienceValidator(fn func(aud []string) error) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			return fn(c.Audience)
+		}))
+	}
+}
+
+// WithSubjectValidator makes Unmarshal pass the 'sub' claim, empty when
+// absent, to fn after the signature is verified. A non-nil error from fn is
+// returned verbatim. Without this option the subject is not checked.
+func WithSubjectValidator(fn func(sub string) error) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			return fn(c.Subject)
+		}))
+	}
+}
+
+// WithSubjectPattern makes Unmarshal reject tokens whose 'sub' claim does not
+// match re with ErrInvalidSubject. A missing 'sub' is matched as the empty
+// string. Anchor re, e.g. `^user:[0-9]+$`, to match the whole subject. A nil
+// re is no pattern and adds no check.
+func WithSubjectPattern(re *regexp.Regexp) Option {
+	if re == nil {
+		return func(*options) {}
+	}
+
+	return WithSubjectValidator(func(sub string) error {
+		if !re.MatchString(sub) {
+			return ErrInvalidSubject
+		}
+
+		return nil
+	})
+}
+
+// WithJTIValidator makes Unmarshal pass the 'jti' claim, empty when absent, to
+// fn after the signature is verified. A non-nil error from fn is returned
+// verbatim. It checks the format of the ID only, not its uniqueness.
+func WithJTIValidator(fn func(jti string) error) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, registeredCheck(func(c *Claims) error {
+			return fn(c.ID)
+		}))
+	}
+}
+
+// WithUUIDJTI makes Unmarshal reject tokens whose 'jti' claim is missing or is
+// not a UUID in its canonical 8-4-4-4-12 hexadecimal form with ErrInvalidJTI.
+func WithUUIDJTI() Option {
+	return WithJTIValidator(func(jti string) error {
+		if !isUUID(jti) {
+			return ErrInvalidJTI
+		}
+
+		return nil
+	})
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// WithAllowedAlgorithms makes Unmarshal reject tokens whose 'alg' header is not
+// one of algs with ErrUnexpectedAlgorithm. The check is exact and runs before
+// the signature is verified. An empty or nil algs rejects every token.
+func WithAllowedAlgorithms(algs ...string) Option {
+	// A copy that is never nil, so an unset list cannot read as "allow all"
+	allowed := append([]string{}, algs...)
+
+	return func(o *options) {
+		o.algorithms = allowed
+	}
+}
+
+// WithMinimumHMACStrength makes Unmarshal reject tokens signed with an HMAC
+// algorithm whose hash is shorter than bits with ErrUnexpectedAlgorithm, e.g.
+// 512 rejects HS256 and HS384 while migrating to HS512. Unlike
+// WithAllowedAlgorithms it sets a floor rather than an exact set, and it
+// leaves non-HMAC algorithms unaffected. The check runs before the signature
+// is verified. Without this option there is no minimum.
+func WithMinimumHMACStrength(bits int) Option {
+	return func(o *options) {
+		o.minHMACBits = bits
+	}
+}
+
+func (o *options) allowsAlgorithm(alg string) bool {
+	if o.minHMACBits > 0 && strings.HasPrefix(strings.ToUpper(alg), "HS") {
+		if hashFunc, err := HashForAlg(alg); err == nil && hashFunc.Size()*8 < o.minHMACBits {
+			return false
+		}
+	}
+
+	if o.algorithms == nil {
+		return true
+	}
+
+	for _, allowed := range o.algorithms {
+		if alg == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithRequiredClaims makes Unmarshal reject tokens missing any of the named
+// claims, or holding null for them, with ErrMissingClaim.
+func WithRequiredClaims(names ...string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, func(t *token) error {
+			fields, err := t.payload.rawClaims()
+
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				if v, ok := fields[name]; !ok || string(v) == "null" {
+					return ErrMissingClaim
+				}
+			}
+
+			return nil
+		})
+	}
+}
+
+// WithTokenUse makes Unmarshal reject tokens whose 'token_use' claim, as issued
+// by Amazon Cognito to tell access tokens from ID tokens, is missing or differs
+// from use with ErrInvalidTokenUse. Without this option the claim is ignored.
+func WithTokenUse(use string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, func(t *token) error {
+			fields, err := t.payload.rawClaims()
+
+			if err != nil {
+				return err
+			}
+
+			var actual string
+
+			if err := json.Unmarshal(fields["token_use"], &actual); err != nil || actual != use {
+				return ErrInvalidTokenUse
+			}
+
+			return nil
+		})
+	}
+}
+
+// WithNonce makes Unmarshal reject tokens whose 'nonce' claim is missing or
+// differs from expected with ErrInvalidNonce. OpenID Connect ID tokens echo
+// the nonce the client sent in its authentication request, binding the token
+// to that request. Without this option the claim is ignored.
+func WithNonce(expected string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, func(t *token) error {
+			fields, err := t.payload.rawClaims()
+
+			if err != nil {
+				return err
+			}
+
+			var nonce string
+
+			if err := json.Unmarshal(fields["nonce"], &nonce); err != nil || nonce != expected {
+				return ErrInvalidNonce
+			}
+
+			return nil
+		})
+	}
+}
+
+// WithAuthorizedParty makes Unmarshal check the 'azp' claim of OpenID Connect
+// ID tokens against clientID (OpenID Connect Core 1.0 section 2): a present
+// 'azp' must equal clientID, and it is required when 'aud' holds more than
+// one audience. Violations return ErrInvalidAuthorizedParty. Without this
+// option the claim is ignored.
+func WithAuthorizedParty(clientID string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, func(t *token) error {
+			fields, err := t.payload.rawClaims()
+
+			if err != nil {
+				return err
+			}
+
+			claims, err := t.payload.registeredClaims()
+
+			if err != nil {
+				return err
+			}
+
+			raw, ok := fields["azp"]
+
+			if !ok {
+				if len(claims.Audience) > 1 {
+					return ErrInvalidAuthorizedParty
+				}
+
+				return nil
+			}
+
+			var azp string
+
+			if err := json.Unmarshal(raw, &azp); err != nil || azp != clientID {
+				return ErrInvalidAuthorizedParty
+			}
+
+			return nil
+		})
+	}
+}
+
+// WithAllowedTypes makes Unmarshal accept tokens whose 'typ' header is one of
+// types instead of only JWT. Media type names are compared case-insensitively
+// and with any "application/" prefix removed (RFC 7515 section 4.1.9).
+func WithAllowedTypes(types ...string) Option {
+	return func(o *options) {
+		o.types = types
+	}
+}
+
+// WithExactType makes Unmarshal accept only tokens whose 'typ' header is typ,
+// e.g. "at+jwt" for OAuth access tokens (RFC 9068), replacing any types given
+// to WithAllowedTypes. The comparison ignores case and any "application/"
+// prefix as the specification requires, but a missing or empty 'typ' header
+// is always rejected.
+func WithExactType(typ string) Option {
+	return func(o *options) {
+		o.types = []string{typ}
+		o.exactType = true
+	}
+}
+
+func (o *options) allowsType(typ string) bool {
+	if o.exactType && typ == "" {
+		return false
+	}
+
+	types := o.types
+
+	if types == nil {
+		types = []string{JWT}
+	}
+
+	for _, allowed := range types {
+		if strings.EqualFold(trimMediaType(typ), trimMediaType(allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func trimMediaType(typ string) string {
+	const prefix = "application/"
+
+	if len(typ) > len(prefix) && strings.EqualFold(typ[:len(prefix)], prefix) {
+		return typ[len(prefix):]
+	}
+
+	return typ
+}
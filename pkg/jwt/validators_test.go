@@ -0,0 +1,1299 @@
+package jwt
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithIssuedAtWindow verifies iat is bounded on both sides when configured
+func TestWithIssuedAtWindow(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	window := WithIssuedAtWindow(24*time.Hour, 5*time.Minute)
+
+	tests := []struct {
+		name    string
+		iat     time.Time
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "within window",
+			iat:     now.Add(-1 * time.Hour),
+			opts:    []Option{window},
+			wantErr: nil,
+		},
+		{
+			name:    "at past bound",
+			iat:     now.Add(-24 * time.Hour),
+			opts:    []Option{window},
+			wantErr: nil,
+		},
+		{
+			name:    "beyond past bound",
+			iat:     now.Add(-25 * time.Hour),
+			opts:    []Option{window},
+			wantErr: ErrIssuedAtOutOfWindow,
+		},
+		{
+			name:    "slightly in the future",
+			iat:     now.Add(2 * time.Minute),
+			opts:    []Option{window},
+			wantErr: nil,
+		},
+		{
+			name:    "beyond future bound",
+			iat:     now.Add(10 * time.Minute),
+			opts:    []Option{window},
+			wantErr: ErrIssuedAtOutOfWindow,
+		},
+		{
+			name:    "default rejects any future iat",
+			iat:     now.Add(2 * time.Minute),
+			wantErr: ErrTokenUsedBeforeIssued,
+		},
+		{
+			name:    "default accepts old iat",
+			iat:     now.Add(-25 * time.Hour),
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, Claims{IssuedAt: tt.iat.Unix()}, secret)
+
+			var decoded Claims
+
+			err := Unmarshal(token, &decoded, secret, append(tt.opts, WithNow(now))...)
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("applies to map claims", func(t *testing.T) {
+		token, _ := Marshal(header, map[string]any{"iat": now.Add(-48 * time.Hour).Unix()}, secret)
+
+		var decoded map[string]any
+
+		if err := Unmarshal(token, &decoded, secret, window, WithNow(now)); err != ErrIssuedAtOutOfWindow {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrIssuedAtOutOfWindow)
+		}
+	})
+
+	t.Run("missing iat passes", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{Subject: "user123"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret, window, WithNow(now)); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
+
+// TestWithMaxNotBeforeFuture verifies 'nbf' far in the future is rejected as malformed
+func TestWithMaxNotBeforeFuture(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	bound := WithMaxNotBeforeFuture(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		nbf     time.Time
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "already valid",
+			nbf:     now.Add(-time.Minute),
+			opts:    []Option{bound},
+			wantErr: nil,
+		},
+		{
+			name:    "within bound is not yet valid",
+			nbf:     now.Add(time.Hour),
+			opts:    []Option{bound},
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:    "at bound is not yet valid",
+			nbf:     now.Add(24 * time.Hour),
+			opts:    []Option{bound},
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:    "beyond bound",
+			nbf:     now.Add(25 * time.Hour),
+			opts:    []Option{bound},
+			wantErr: ErrNotBeforeTooFarAhead,
+		},
+		{
+			name:    "years ahead",
+			nbf:     now.AddDate(10, 0, 0),
+			opts:    []Option{bound},
+			wantErr: ErrNotBeforeTooFarAhead,
+		},
+		{
+			name:    "leeway extends the bound",
+			nbf:     now.Add(24*time.Hour + time.Minute),
+			opts:    []Option{bound, WithLeeway(2 * time.Minute)},
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:    "leeway still accepts nearly valid tokens",
+			nbf:     now.Add(time.Minute),
+			opts:    []Option{bound, WithLeeway(2 * time.Minute)},
+			wantErr: nil,
+		},
+		{
+			name:    "default defers validity",
+			nbf:     now.AddDate(10, 0, 0),
+			wantErr: ErrTokenNotValidYet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, Claims{NotBefore: tt.nbf.Unix()}, secret)
+
+			err := Unmarshal(token, &Claims{}, secret, append(tt.opts, WithNow(now))...)
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithMaxTTL verifies tokens living longer than the maximum are rejected
+func TestWithMaxTTL(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	iat := now.Add(-time.Hour).Unix()
+	maxTTL := WithMaxTTL(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "within limit",
+			claims:  Claims{IssuedAt: iat, ExpiresAt: iat + 2*3600},
+			opts:    []Option{maxTTL},
+			wantErr: nil,
+		},
+		{
+			name:    "at limit",
+			claims:  Claims{IssuedAt: iat, ExpiresAt: iat + 24*3600},
+			opts:    []Option{maxTTL},
+			wantErr: nil,
+		},
+		{
+			name:    "beyond limit",
+			claims:  Claims{IssuedAt: iat, ExpiresAt: iat + 24*3600 + 1},
+			opts:    []Option{maxTTL},
+			wantErr: ErrTokenTTLExceeded,
+		},
+		{
+			name:    "missing iat skipped",
+			claims:  Claims{ExpiresAt: iat + 48*3600},
+			opts:    []Option{maxTTL},
+			wantErr: nil,
+		},
+		{
+			name:    "missing exp skipped",
+			claims:  Claims{IssuedAt: iat},
+			opts:    []Option{maxTTL},
+			wantErr: nil,
+		},
+		{
+			name:    "missing claim rejected when required",
+			claims:  Claims{IssuedAt: iat},
+			opts:    []Option{maxTTL, WithRequiredClaims("exp", "iat")},
+			wantErr: ErrMissingClaim,
+		},
+		{
+			name:    "default has no limit",
+			claims:  Claims{IssuedAt: iat, ExpiresAt: iat + 365*24*3600},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, tt.claims, secret)
+
+			err := Unmarshal(token, &Claims{}, secret, append(tt.opts, WithNow(now))...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithFreshness verifies only recently issued tokens pass
+func TestWithFreshness(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr error
+	}{
+		{
+			name:    "just issued",
+			claims:  Claims{IssuedAt: now.Unix()},
+			wantErr: nil,
+		},
+		{
+			name:    "at bound",
+			claims:  Claims{IssuedAt: now.Add(-30 * time.Second).Unix()},
+			wantErr: nil,
+		},
+		{
+			name:    "beyond bound",
+			claims:  Claims{IssuedAt: now.Add(-31 * time.Second).Unix()},
+			wantErr: ErrTokenNotFresh,
+		},
+		{
+			name:    "stale but unexpired",
+			claims:  Claims{IssuedAt: now.Add(-time.Hour).Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+			wantErr: ErrTokenNotFresh,
+		},
+		{
+			name:    "missing iat",
+			claims:  Claims{ExpiresAt: now.Add(time.Hour).Unix()},
+			wantErr: ErrTokenNotFresh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, tt.claims, secret)
+
+			err := Unmarshal(token, &Claims{}, secret, WithNow(now), WithFreshness(30*time.Second))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("expiry is reported first", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{IssuedAt: now.Add(-2 * time.Hour).Unix(), ExpiresAt: now.Add(-time.Hour).Unix()}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret, WithNow(now), WithFreshness(30*time.Second)); err != ErrTokenExpired {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrTokenExpired)
+		}
+	})
+}
+
+// TestWithAudienceValidator verifies custom audience logic receives the normalized audience
+func TestWithAudienceValidator(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	errAudience := errors.New("audience rejected")
+
+	wildcard := func(aud []string) error {
+		for _, a := range aud {
+			if strings.HasPrefix(a, "api:") {
+				return nil
+			}
+		}
+
+		return errAudience
+	}
+
+	tests := []struct {
+		name    string
+		claims  any
+		wantErr error
+	}{
+		{
+			name:    "single audience accepted",
+			claims:  Claims{Audience: Audience{"api:orders"}},
+			wantErr: nil,
+		},
+		{
+			name:    "array audience accepted",
+			claims:  Claims{Audience: Audience{"web", "api:billing"}},
+			wantErr: nil,
+		},
+		{
+			name:    "audience rejected",
+			claims:  Claims{Audience: Audience{"web"}},
+			wantErr: errAudience,
+		},
+		{
+			name:    "missing audience rejected",
+			claims:  Claims{Subject: "user123"},
+			wantErr: errAudience,
+		},
+		{
+			name:    "map claims are checked",
+			claims:  map[string]any{"aud": []string{"api:orders"}},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var decoded map[string]any
+
+			err = Unmarshal(token, &decoded, secret, WithAudienceValidator(wildcard))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("receives the decoded slice", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{Audience: Audience{"a", "b"}}, secret)
+
+		var got []string
+
+		err := Unmarshal(token, &Claims{}, secret, WithAudienceValidator(func(aud []string) error {
+			got = aud
+			return nil
+		}))
+
+		if err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got, []string{"a", "b"}) {
+			t.Errorf("audience = %v, want [a b]", got)
+		}
+	})
+
+	t.Run("not called on signature mismatch", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{Audience: Audience{"api:orders"}}, []byte("other-secret"))
+
+		called := false
+
+		err := Unmarshal(token, &Claims{}, secret, WithAudienceValidator(func([]string) error {
+			called = true
+			return nil
+		}))
+
+		if err != ErrSignatureMismatch || called {
+			t.Errorf("Unmarshal() error = %v, called = %v", err, called)
+		}
+	})
+}
+
+// TestWithIssuer verifies exact and normalized issuer matching
+func TestWithIssuer(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		iss     string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "exact match",
+			iss:     "https://auth.example.com",
+			opts:    []Option{WithIssuer("https://auth.example.com")},
+			wantErr: nil,
+		},
+		{
+			name:    "trailing slash rejected by default",
+			iss:     "https://auth.example.com/",
+			opts:    []Option{WithIssuer("https://auth.example.com")},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "trailing slash accepted with normalization",
+			iss:     "https://auth.example.com/",
+			opts:    []Option{WithIssuer("https://auth.example.com"), WithIssuerNormalization(NormalizeURL)},
+			wantErr: nil,
+		},
+		{
+			name:    "normalization applies to the expected value",
+			iss:     "https://auth.example.com",
+			opts:    []Option{WithIssuerNormalization(NormalizeURL), WithIssuer("HTTPS://Auth.Example.com/")},
+			wantErr: nil,
+		},
+		{
+			name:    "different issuer rejected with normalization",
+			iss:     "https://evil.example.com/",
+			opts:    []Option{WithIssuer("https://auth.example.com"), WithIssuerNormalization(NormalizeURL)},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "missing issuer rejected",
+			iss:     "",
+			opts:    []Option{WithIssuer("https://auth.example.com")},
+			wantErr: ErrInvalidIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, Claims{Issuer: tt.iss}, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithAudience verifies audience matching against any expected value
+func TestWithAudience(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		aud     Audience
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "single audience match",
+			aud:     Audience{"api"},
+			opts:    []Option{WithAudience("api")},
+			wantErr: nil,
+		},
+		{
+			name:    "one of several token audiences",
+			aud:     Audience{"web", "api"},
+			opts:    []Option{WithAudience("api")},
+			wantErr: nil,
+		},
+		{
+			name:    "one of several expected audiences",
+			aud:     Audience{"web"},
+			opts:    []Option{WithAudience("api", "web")},
+			wantErr: nil,
+		},
+		{
+			name:    "no match",
+			aud:     Audience{"web"},
+			opts:    []Option{WithAudience("api")},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "missing audience",
+			aud:     nil,
+			opts:    []Option{WithAudience("api")},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "normalized match",
+			aud:     Audience{"https://API.example.com/"},
+			opts:    []Option{WithAudience("https://api.example.com"), WithAudienceNormalization(NormalizeURL)},
+			wantErr: nil,
+		},
+		{
+			name:    "optional audience absent",
+			aud:     nil,
+			opts:    []Option{WithAudience("api"), WithAudienceOptional()},
+			wantErr: nil,
+		},
+		{
+			name:    "optional audience present and matching",
+			aud:     Audience{"api"},
+			opts:    []Option{WithAudienceOptional(), WithAudience("api")},
+			wantErr: nil,
+		},
+		{
+			name:    "optional audience present without a match",
+			aud:     Audience{"web"},
+			opts:    []Option{WithAudience("api"), WithAudienceOptional()},
+			wantErr: ErrInvalidAudience,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(header, Claims{Audience: tt.aud}, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("optional audience empty array counts as present", func(t *testing.T) {
+		token, _ := Marshal(header, map[string]any{"aud": []string{}}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret, WithAudience("api"), WithAudienceOptional()); err != ErrInvalidAudience {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidAudience)
+		}
+	})
+}
+
+// TestWithSubjectPattern verifies subjects not matching the pattern are rejected
+func TestWithSubjectPattern(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	pattern := regexp.MustCompile(`^user:[0-9]+$`)
+
+	tests := []struct {
+		name    string
+		sub     string
+		wantErr error
+	}{
+		{
+			name:    "matching subject",
+			sub:     "user:42",
+			wantErr: nil,
+		},
+		{
+			name:    "missing subject",
+			sub:     "",
+			wantErr: ErrInvalidSubject,
+		},
+		{
+			name:    "wrong prefix",
+			sub:     "admin:42",
+			wantErr: ErrInvalidSubject,
+		},
+		{
+			name:    "trailing garbage",
+			sub:     "user:42\n",
+			wantErr: ErrInvalidSubject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, Claims{Subject: tt.sub}, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithSubjectPattern(pattern))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("not checked without the option", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{Subject: "admin:42"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+
+	t.Run("nil pattern adds no check", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{Subject: "admin:42"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret, WithSubjectPattern(nil)); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
+
+// TestWithUUIDJTI verifies only canonical UUIDs are accepted as the 'jti' claim
+func TestWithUUIDJTI(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		jti     string
+		wantErr error
+	}{
+		{
+			name:    "lowercase UUID",
+			jti:     "123e4567-e89b-12d3-a456-426614174000",
+			wantErr: nil,
+		},
+		{
+			name:    "uppercase UUID",
+			jti:     "123E4567-E89B-12D3-A456-426614174000",
+			wantErr: nil,
+		},
+		{
+			name:    "missing jti",
+			jti:     "",
+			wantErr: ErrInvalidJTI,
+		},
+		{
+			name:    "not a UUID",
+			jti:     "token-1",
+			wantErr: ErrInvalidJTI,
+		},
+		{
+			name:    "missing hyphens",
+			jti:     "123e4567e89b12d3a456426614174000",
+			wantErr: ErrInvalidJTI,
+		},
+		{
+			name:    "misplaced hyphen",
+			jti:     "123e456-7e89b-12d3-a456-426614174000",
+			wantErr: ErrInvalidJTI,
+		},
+		{
+			name:    "non-hex digit",
+			jti:     "123e4567-e89b-12d3-a456-42661417400g",
+			wantErr: ErrInvalidJTI,
+		},
+		{
+			name:    "braced UUID",
+			jti:     "{123e4567-e89b-12d3-a456-426614174000}",
+			wantErr: ErrInvalidJTI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, Claims{ID: tt.jti}, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithUUIDJTI())
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("not checked without the option", func(t *testing.T) {
+		token, _ := Marshal(header, Claims{ID: "token-1"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v, want nil", err)
+		}
+	})
+}
+
+// TestWithJTIValidator verifies custom 'jti' checks receive the decoded ID
+func TestWithJTIValidator(t *testing.T) {
+	secret := []byte("test-secret")
+	errJTI := errors.New("jti rejected")
+
+	token, _ := Marshal(Header{Alg: HS256}, Claims{ID: "abc"}, secret)
+
+	var got string
+
+	err := Unmarshal(token, &Claims{}, secret, WithJTIValidator(func(jti string) error {
+		got = jti
+		return errJTI
+	}))
+
+	if err != errJTI {
+		t.Errorf("Unmarshal() error = %v, want %v", err, errJTI)
+	}
+
+	if got != "abc" {
+		t.Errorf("jti = %q, want %q", got, "abc")
+	}
+}
+
+// TestWithAllowedAlgorithms verifies only allowlisted algorithms are accepted
+func TestWithAllowedAlgorithms(t *testing.T) {
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name    string
+		alg     string
+		allowed []string
+		wantErr error
+	}{
+		{
+			name:    "allowed algorithm",
+			alg:     HS256,
+			allowed: []string{HS256},
+			wantErr: nil,
+		},
+		{
+			name:    "one of several",
+			alg:     HS512,
+			allowed: []string{HS256, HS512},
+			wantErr: nil,
+		},
+		{
+			name:    "algorithm not allowed",
+			alg:     HS384,
+			allowed: []string{HS256},
+			wantErr: ErrUnexpectedAlgorithm,
+		},
+		{
+			name:    "comparison is exact",
+			alg:     "hs256",
+			allowed: []string{HS256},
+			wantErr: ErrUnexpectedAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(Header{Alg: tt.alg}, Claims{}, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithAllowedAlgorithms(tt.allowed...))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("checked before the signature", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS384}, Claims{}, secret)
+
+		err := Unmarshal(token, &Claims{}, []byte("wrong"), WithAllowedAlgorithms(HS256))
+
+		if err != ErrUnexpectedAlgorithm {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+
+	t.Run("nil list rejects every algorithm", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS384}, Claims{}, secret)
+
+		var unset []string
+
+		if err := Unmarshal(token, &Claims{}, secret, WithAllowedAlgorithms(unset...)); err != ErrUnexpectedAlgorithm {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+
+		if err := UnmarshalStrict(token, &Claims{}, secret, WithAllowedAlgorithms(unset...)); err != ErrUnexpectedAlgorithm {
+			t.Errorf("UnmarshalStrict() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+
+	t.Run("caller slice is copied", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS384}, Claims{}, secret)
+
+		algs := []string{HS256}
+		opt := WithAllowedAlgorithms(algs...)
+		algs[0] = HS384
+
+		if err := Unmarshal(token, &Claims{}, secret, opt); err != ErrUnexpectedAlgorithm {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+}
+
+// TestWithMinimumHMACStrength verifies HMAC algorithms below the floor are rejected
+func TestWithMinimumHMACStrength(t *testing.T) {
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name    string
+		alg     string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "HS512 at a 512-bit floor", alg: HS512, opts: []Option{WithMinimumHMACStrength(512)}},
+		{name: "HS384 below a 512-bit floor", alg: HS384, opts: []Option{WithMinimumHMACStrength(512)}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "HS256 below a 512-bit floor", alg: HS256, opts: []Option{WithMinimumHMACStrength(512)}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "HS384 at a 384-bit floor", alg: HS384, opts: []Option{WithMinimumHMACStrength(384)}},
+		{name: "combined with an allowlist", alg: HS512, opts: []Option{WithMinimumHMACStrength(384), WithAllowedAlgorithms(HS256)}, wantErr: ErrUnexpectedAlgorithm},
+		{name: "no minimum by default", alg: HS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: tt.alg}, Claims{}, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("checked before the signature", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{}, secret)
+
+		if err := Unmarshal(token, &Claims{}, []byte("wrong"), WithMinimumHMACStrength(512)); err != ErrUnexpectedAlgorithm {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnexpectedAlgorithm)
+		}
+	})
+}
+
+// TestWithRequiredClaims verifies tokens missing a required claim are rejected
+func TestWithRequiredClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		claims  any
+		wantErr error
+	}{
+		{
+			name:    "all present",
+			claims:  Claims{Subject: "user123", ExpiresAt: exp},
+			wantErr: nil,
+		},
+		{
+			name:    "missing claim",
+			claims:  Claims{Subject: "user123"},
+			wantErr: ErrMissingClaim,
+		},
+		{
+			name:    "null claim",
+			claims:  map[string]any{"sub": "user123", "exp": nil},
+			wantErr: ErrMissingClaim,
+		},
+		{
+			name:    "custom claim",
+			claims:  map[string]any{"sub": "user123", "exp": exp, "role": "admin"},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &map[string]any{}, secret, WithRequiredClaims("sub", "exp"))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithTokenUse verifies the 'token_use' claim must match the expected use
+func TestWithTokenUse(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		claims  map[string]any
+		wantErr error
+	}{
+		{
+			name:    "matching use",
+			claims:  map[string]any{"sub": "user123", "token_use": "access"},
+			wantErr: nil,
+		},
+		{
+			name:    "id token",
+			claims:  map[string]any{"sub": "user123", "token_use": "id"},
+			wantErr: ErrInvalidTokenUse,
+		},
+		{
+			name:    "missing claim",
+			claims:  map[string]any{"sub": "user123"},
+			wantErr: ErrInvalidTokenUse,
+		},
+		{
+			name:    "non-string claim",
+			claims:  map[string]any{"sub": "user123", "token_use": 1},
+			wantErr: ErrInvalidTokenUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithTokenUse("access"))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("ignored without the option", func(t *testing.T) {
+		token, _ := Marshal(header, map[string]any{"token_use": "id"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v, want nil", err)
+		}
+	})
+}
+
+// TestWithNonce verifies the nonce claim must match when configured
+func TestWithNonce(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		claims  map[string]any
+		wantErr error
+	}{
+		{
+			name:    "matching nonce",
+			claims:  map[string]any{"sub": "user123", "nonce": "n-0S6_WzA2Mj"},
+			wantErr: nil,
+		},
+		{
+			name:    "mismatching nonce",
+			claims:  map[string]any{"sub": "user123", "nonce": "other"},
+			wantErr: ErrInvalidNonce,
+		},
+		{
+			name:    "missing claim",
+			claims:  map[string]any{"sub": "user123"},
+			wantErr: ErrInvalidNonce,
+		},
+		{
+			name:    "non-string claim",
+			claims:  map[string]any{"sub": "user123", "nonce": 1},
+			wantErr: ErrInvalidNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithNonce("n-0S6_WzA2Mj"))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("ignored without the option", func(t *testing.T) {
+		token, _ := Marshal(header, map[string]any{"nonce": "other"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v, want nil", err)
+		}
+	})
+}
+
+// TestWithAuthorizedParty verifies 'azp' is matched when present and required for several audiences
+func TestWithAuthorizedParty(t *testing.T) {
+	secret := []byte("test-secret")
+	header := Header{Alg: HS256, Typ: JWT}
+
+	tests := []struct {
+		name    string
+		claims  map[string]any
+		wantErr error
+	}{
+		{
+			name:    "single audience without azp",
+			claims:  map[string]any{"aud": "client-a"},
+			wantErr: nil,
+		},
+		{
+			name:    "single audience with matching azp",
+			claims:  map[string]any{"aud": "client-a", "azp": "client-a"},
+			wantErr: nil,
+		},
+		{
+			name:    "single audience with mismatching azp",
+			claims:  map[string]any{"aud": "client-a", "azp": "client-b"},
+			wantErr: ErrInvalidAuthorizedParty,
+		},
+		{
+			name:    "multiple audiences with matching azp",
+			claims:  map[string]any{"aud": []string{"client-a", "api"}, "azp": "client-a"},
+			wantErr: nil,
+		},
+		{
+			name:    "multiple audiences without azp",
+			claims:  map[string]any{"aud": []string{"client-a", "api"}},
+			wantErr: ErrInvalidAuthorizedParty,
+		},
+		{
+			name:    "multiple audiences with mismatching azp",
+			claims:  map[string]any{"aud": []string{"client-a", "api"}, "azp": "api"},
+			wantErr: ErrInvalidAuthorizedParty,
+		},
+		{
+			name:    "non-string azp",
+			claims:  map[string]any{"aud": "client-a", "azp": 1},
+			wantErr: ErrInvalidAuthorizedParty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Marshal(header, tt.claims, secret)
+
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			err = Unmarshal(token, &Claims{}, secret, WithAuthorizedParty("client-a"))
+
+			if err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("ignored without the option", func(t *testing.T) {
+		token, _ := Marshal(header, map[string]any{"aud": []string{"client-a", "api"}, "azp": "other"}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+}
+
+// TestWithAllowedTypes verifies the accepted 'typ' headers can be configured
+func TestWithAllowedTypes(t *testing.T) {
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name    string
+		typ     string
+		allowed []string
+		wantErr error
+	}{
+		{
+			name:    "default accepts JWT",
+			typ:     JWT,
+			wantErr: nil,
+		},
+		{
+			name:    "default accepts the application prefix",
+			typ:     "application/jwt",
+			wantErr: nil,
+		},
+		{
+			name:    "default rejects other types",
+			typ:     "at+jwt",
+			wantErr: unsupportedTypeError{typ: "at+jwt"},
+		},
+		{
+			name:    "allowed type",
+			typ:     "at+jwt",
+			allowed: []string{"at+jwt"},
+			wantErr: nil,
+		},
+		{
+			name:    "allowed type ignores case and prefix",
+			typ:     "Application/AT+JWT",
+			allowed: []string{"at+jwt"},
+			wantErr: nil,
+		},
+		{
+			name:    "allowlist replaces JWT",
+			typ:     JWT,
+			allowed: []string{"at+jwt"},
+			wantErr: unsupportedTypeError{typ: JWT},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256, Typ: tt.typ}, Claims{}, secret)
+
+			var opts []Option
+
+			if tt.allowed != nil {
+				opts = append(opts, WithAllowedTypes(tt.allowed...))
+			}
+
+			if err := Unmarshal(token, &Claims{}, secret, opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithExactType verifies a single 'typ' header can be pinned
+func TestWithExactType(t *testing.T) {
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name    string
+		header  string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "exact type",
+			header:  `{"alg":"HS256","typ":"at+jwt"}`,
+			opts:    []Option{WithExactType("at+jwt")},
+			wantErr: nil,
+		},
+		{
+			name:    "ignores case and prefix",
+			header:  `{"alg":"HS256","typ":"application/AT+JWT"}`,
+			opts:    []Option{WithExactType("at+jwt")},
+			wantErr: nil,
+		},
+		{
+			name:    "rejects JWT",
+			header:  `{"alg":"HS256","typ":"JWT"}`,
+			opts:    []Option{WithExactType("at+jwt")},
+			wantErr: unsupportedTypeError{typ: JWT},
+		},
+		{
+			name:    "rejects missing type",
+			header:  `{"alg":"HS256"}`,
+			opts:    []Option{WithExactType("at+jwt")},
+			wantErr: unsupportedTypeError{typ: ""},
+		},
+		{
+			name:    "rejects empty type even when pinned",
+			header:  `{"alg":"HS256","typ":""}`,
+			opts:    []Option{WithExactType("")},
+			wantErr: unsupportedTypeError{typ: ""},
+		},
+		{
+			name:    "replaces allowed types",
+			header:  `{"alg":"HS256","typ":"JWT"}`,
+			opts:    []Option{WithAllowedTypes(JWT, "at+jwt"), WithExactType("at+jwt")},
+			wantErr: unsupportedTypeError{typ: JWT},
+		},
+		{
+			name:    "default unchanged",
+			header:  `{"alg":"HS256","typ":"JWT"}`,
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := encodeJWTBase64([]byte(tt.header))
+			payload := encodeJWTBase64([]byte(`{}`))
+			signature, _ := ComputeSignature(header+"."+payload, HS256, secret)
+
+			if err := Unmarshal(header+"."+payload+"."+signature, &Claims{}, secret, tt.opts...); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithSubjectValidator verifies structured subjects can be checked by custom logic
+func TestWithSubjectValidator(t *testing.T) {
+	secret := []byte("test-secret")
+	errFormat := errors.New("subject is not tenant:user")
+	errTenant := errors.New("unknown tenant")
+
+	tenantUser := func(sub string) error {
+		tenant, user, ok := strings.Cut(sub, ":")
+
+		if !ok || tenant == "" || user == "" {
+			return errFormat
+		}
+
+		if tenant != "acme" {
+			return errTenant
+		}
+
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		sub     string
+		wantErr error
+	}{
+		{name: "valid subject", sub: "acme:user123", wantErr: nil},
+		{name: "unknown tenant", sub: "other:user123", wantErr: errTenant},
+		{name: "unstructured subject", sub: "user123", wantErr: errFormat},
+		{name: "missing subject", sub: "", wantErr: errFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256}, Claims{Subject: tt.sub}, secret)
+
+			if err := Unmarshal(token, &Claims{}, secret, WithSubjectValidator(tenantUser)); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithMaxAudiences verifies oversized audience arrays are rejected before matching
+func TestWithMaxAudiences(t *testing.T) {
+	secret := []byte("test-secret")
+
+	oversized := make(Audience, 10000)
+
+	for i := range oversized {
+		oversized[i] = "aud-" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name    string
+		aud     Audience
+		wantErr error
+	}{
+		{name: "within the limit", aud: Audience{"a", "b", "c"}, wantErr: nil},
+		{name: "missing audience", aud: nil, wantErr: nil},
+		{name: "one over the limit", aud: Audience{"a", "b", "c", "d"}, wantErr: ErrMalformedClaims},
+		{name: "oversized array", aud: oversized, wantErr: ErrMalformedClaims},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Marshal(Header{Alg: HS256}, Claims{Audience: tt.aud}, secret)
+
+			matched := false
+
+			match := WithAudienceValidator(func([]string) error {
+				matched = true
+				return nil
+			})
+
+			if err := Unmarshal(token, &Claims{}, secret, match, WithMaxAudiences(3)); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if matched != (tt.wantErr == nil) {
+				t.Errorf("audience validator called = %v, want %v", matched, tt.wantErr == nil)
+			}
+		})
+	}
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		token, _ := Marshal(Header{Alg: HS256}, Claims{Audience: oversized}, secret)
+
+		if err := Unmarshal(token, &Claims{}, secret); err != nil {
+			t.Errorf("Unmarshal() error = %v, want nil", err)
+		}
+	})
+}
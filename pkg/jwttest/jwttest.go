@@ -0,0 +1,88 @@
+// Package jwttest provides helpers for minting valid and deliberately invalid
+// tokens in tests of code that consumes JWTs. All tokens are signed with HS256.
+package jwttest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/othonhugo/gotoken/pkg/jwt"
+)
+
+// header is the header of every token minted by this package
+var header = jwt.Header{Alg: jwt.HS256, Typ: jwt.JWT}
+
+// MustToken returns a token for claims signed with secret, failing the test
+// when the token cannot be created.
+func MustToken(t testing.TB, claims any, secret []byte) string {
+	t.Helper()
+
+	token, err := jwt.Marshal(header, claims, secret)
+
+	if err != nil {
+		t.Fatalf("jwttest: marshal token: %v", err)
+	}
+
+	return token
+}
+
+// ExpiredToken returns a token for claims whose 'exp' lies an hour in the
+// past, so verification fails with jwt.ErrTokenExpired.
+func ExpiredToken(t testing.TB, claims jwt.Claims, secret []byte) string {
+	t.Helper()
+
+	claims.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+
+	if claims.IssuedAt != 0 && claims.IssuedAt >= claims.ExpiresAt {
+		claims.IssuedAt = claims.ExpiresAt - 1
+	}
+
+	return MustToken(t, claims, secret)
+}
+
+// NotYetValidToken returns a token for claims whose 'nbf' lies an hour in the
+// future, so verification fails with jwt.ErrTokenNotValidYet.
+func NotYetValidToken(t testing.TB, claims jwt.Claims, secret []byte) string {
+	t.Helper()
+
+	claims.NotBefore = time.Now().Add(time.Hour).Unix()
+
+	return MustToken(t, claims, secret)
+}
+
+// WrongSignature returns a token for claims signed with a secret other than
+// secret, so verification with secret fails with jwt.ErrSignatureMismatch.
+func WrongSignature(t testing.TB, claims any, secret []byte) string {
+	t.Helper()
+
+	wrong := append([]byte("jwttest-wrong-"), secret...)
+
+	return MustToken(t, claims, wrong)
+}
+
+// TamperedToken returns token with its payload altered after signing. The
+// result is still well-formed, so verification fails with
+// jwt.ErrSignatureMismatch rather than jwt.ErrInvalidToken.
+func TamperedToken(t testing.TB, token string) string {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 || parts[1] == "" {
+		t.Fatalf("jwttest: tamper token: not a compact JWS: %q", token)
+	}
+
+	payload := []byte(parts[1])
+
+	// Swap the first character for another from the base64url alphabet
+	if payload[0] == 'A' {
+		payload[0] = 'B'
+	} else {
+		payload[0] = 'A'
+	}
+
+	parts[1] = string(payload)
+
+	return strings.Join(parts, ".")
+}
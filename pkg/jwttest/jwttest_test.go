@@ -0,0 +1,65 @@
+package jwttest
+
+import (
+	"testing"
+
+	"github.com/othonhugo/gotoken/pkg/jwt"
+)
+
+// TestHelpers verifies each helper produces a token failing with the documented error
+func TestHelpers(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := jwt.Claims{Subject: "user123"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			token:   MustToken(t, claims, secret),
+			wantErr: nil,
+		},
+		{
+			name:    "map claims",
+			token:   MustToken(t, map[string]any{"sub": "user123"}, secret),
+			wantErr: nil,
+		},
+		{
+			name:    "expired token",
+			token:   ExpiredToken(t, claims, secret),
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "expired token with iat",
+			token:   ExpiredToken(t, jwt.Claims{IssuedAt: 1 << 40}, secret),
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "not yet valid token",
+			token:   NotYetValidToken(t, claims, secret),
+			wantErr: jwt.ErrTokenNotValidYet,
+		},
+		{
+			name:    "wrong signature",
+			token:   WrongSignature(t, claims, secret),
+			wantErr: jwt.ErrSignatureMismatch,
+		},
+		{
+			name:    "tampered token",
+			token:   TamperedToken(t, MustToken(t, claims, secret)),
+			wantErr: jwt.ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded jwt.Claims
+
+			if err := jwt.Unmarshal(tt.token, &decoded, secret); err != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}